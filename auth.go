@@ -0,0 +1,250 @@
+package scrapingo
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gobwas/glob"
+	"github.com/gobwas/glob/match"
+)
+
+//為請求加上認證資訊
+//可自行定義並透過Collector.AddAuthenticator添加
+type Authenticator interface {
+	//每次請求前調用(包含重試) 為req加上認證資訊
+	Authenticate(req *http.Request) error
+	//收到401時以失敗的req調用 返回true時會重新調用Authenticate並重試一次
+	Invalidate(req *http.Request) bool
+}
+
+//URL符合DomainGlob時使用Authenticator
+type Auth struct {
+	//匹配URL的glob 例: *api.example.com/*
+	DomainGlob    string
+	Authenticator Authenticator
+	urlGlob       glob.Glob
+}
+
+//初始化Auth
+func (a *Auth) register() (err error) {
+	a.urlGlob, err = glob.Compile(a.DomainGlob)
+	if err != nil {
+		return err
+	}
+	if _, ok := a.urlGlob.(match.Nothing); ok {
+		return ErrNoDomainPattern
+	}
+	return nil
+}
+
+func (a *Auth) Match(URL string) bool {
+	return a.urlGlob.Match(URL)
+}
+
+func (a *Auth) String() string {
+	return fmt.Sprintf("DomainGlob:%s Authenticator:%T", a.DomainGlob, a.Authenticator)
+}
+
+//HTTP Basic認證
+type BasicAuth struct {
+	Username string
+	Password string
+}
+
+func (b *BasicAuth) Authenticate(req *http.Request) error {
+	req.SetBasicAuth(b.Username, b.Password)
+	return nil
+}
+
+//帳號密碼不會改變 不進行重試
+func (b *BasicAuth) Invalidate(*http.Request) bool {
+	return false
+}
+
+//固定的Bearer token
+type BearerToken struct {
+	Token string
+}
+
+func (b *BearerToken) Authenticate(req *http.Request) error {
+	req.Header.Set("Authorization", "Bearer "+b.Token)
+	return nil
+}
+
+//token不會改變 不進行重試
+func (b *BearerToken) Invalidate(*http.Request) bool {
+	return false
+}
+
+//OAuth2 client credentials 取得的token會緩存至過期前
+//收到401時會重新取得token 多個請求同時失敗時只會重新取得一次
+type ClientCredentials struct {
+	//取得token的URL
+	TokenURL     string
+	ClientID     string
+	ClientSecret string
+	Scopes       []string
+	//取得token時的其他參數
+	Params url.Values
+	//取得token所使用的Client 為nil時使用http.DefaultClient
+	Client *http.Client
+	//在過期前多久重新取得token 默認為10秒
+	ExpiryDelta time.Duration
+
+	mu     sync.Mutex
+	token  string
+	expiry time.Time
+}
+
+//token endpoint的回應
+type tokenResponse struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	ExpiresIn   int64  `json:"expires_in"`
+	Error       string `json:"error"`
+}
+
+func (c *ClientCredentials) Authenticate(req *http.Request) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.token == "" || c.expired() {
+		if err := c.fetch(req); err != nil {
+			return err
+		}
+	}
+	req.Header.Set("Authorization", "Bearer "+c.token)
+	return nil
+}
+
+//失敗的請求所使用的token為當前的token時才清除 避免重複取得
+func (c *ClientCredentials) Invalidate(req *http.Request) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.token != "" && req.Header.Get("Authorization") == "Bearer "+c.token {
+		c.token = ""
+	}
+	return true
+}
+
+func (c *ClientCredentials) expired() bool {
+	if c.expiry.IsZero() {
+		return false
+	}
+	delta := c.ExpiryDelta
+	if delta == 0 {
+		delta = 10 * time.Second
+	}
+	return time.Now().Add(delta).After(c.expiry)
+}
+
+//向TokenURL取得token client_id以及client_secret以Basic認證傳送
+func (c *ClientCredentials) fetch(r *http.Request) error {
+	form := url.Values{"grant_type": {"client_credentials"}}
+	if len(c.Scopes) > 0 {
+		form.Set("scope", strings.Join(c.Scopes, " "))
+	}
+	for k, v := range c.Params {
+		form[k] = v
+	}
+	req, err := http.NewRequestWithContext(r.Context(), http.MethodPost, c.TokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", formURLEncoded)
+	req.Header.Set("Accept", "application/json")
+	req.SetBasicAuth(url.QueryEscape(c.ClientID), url.QueryEscape(c.ClientSecret))
+
+	client := c.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	var token tokenResponse
+	json.Unmarshal(body, &token)
+	if resp.StatusCode != http.StatusOK || token.AccessToken == "" {
+		return &AuthError{StatusCode: resp.StatusCode, Code: token.Error, Body: body}
+	}
+	c.token = token.AccessToken
+	c.expiry = time.Time{}
+	if token.ExpiresIn > 0 {
+		c.expiry = time.Now().Add(time.Duration(token.ExpiresIn) * time.Second)
+	}
+	return nil
+}
+
+//添加Auth至Transfer中 當register()返回error時添加失敗
+func (t *Transfer) AddAuth(a *Auth) (err error) {
+	t.rw.Lock()
+	defer t.rw.Unlock()
+	if err = a.register(); err == nil {
+		t.Auths = append(t.Auths, a)
+	}
+	return err
+}
+
+//取得註冊過的Authenticator對指定的URL進行認證
+func (t *Transfer) getAuthenticator(URL string) Authenticator {
+	t.rw.RLock()
+	defer t.rw.RUnlock()
+	for _, a := range t.Auths {
+		if a.Match(URL) {
+			return a.Authenticator
+		}
+	}
+	return nil
+}
+
+//為請求加上認證資訊 Request已設置Authorization時不進行認證
+//返回加上認證資訊的複製 以及所使用的Authenticator
+func (t *Transfer) authenticate(req *http.Request) (*http.Request, Authenticator, error) {
+	auth := t.getAuthenticator(req.URL.String())
+	if auth == nil || req.Header.Get("Authorization") != "" {
+		return req, nil, nil
+	}
+	authReq := req.Clone(req.Context())
+	if err := auth.Authenticate(authReq); err != nil {
+		return nil, nil, err
+	}
+	return authReq, auth, nil
+}
+
+//進行請求 收到401時通知Authenticator 重新認證後重試一次
+//Body無法重新讀取時直接返回401的Response
+func (t *Transfer) authRoundTrip(r *Request, req *http.Request, MaxBodySize int) (*Response, error) {
+	authReq, auth, err := t.authenticate(req)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := t.roundTrip(r, authReq, MaxBodySize)
+	if err != nil || auth == nil || resp.StatusCode != http.StatusUnauthorized || !auth.Invalidate(authReq) {
+		return resp, err
+	}
+	replay, err := replayRequest(req)
+	if err != nil {
+		return resp, nil
+	}
+	if authReq, _, err = t.authenticate(replay); err != nil {
+		return nil, err
+	}
+	return t.roundTrip(r, authReq, MaxBodySize)
+}
+
+//添加URL符合domainGlob時所使用的Authenticator 依照添加順序匹配
+func (c *Collector) AddAuthenticator(domainGlob string, a Authenticator) error {
+	return c.transfer.AddAuth(&Auth{DomainGlob: domainGlob, Authenticator: a})
+}
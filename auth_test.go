@@ -0,0 +1,126 @@
+package scrapingo
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+//模擬token endpoint以及只接受最新token的API
+type tokenServer struct {
+	*httptest.Server
+	issued int32
+	mu     sync.Mutex
+	valid  string
+}
+
+func newTokenServer(t *testing.T) *tokenServer {
+	s := &tokenServer{}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/token":
+			id, secret, ok := r.BasicAuth()
+			if !ok || id != "id" || secret != "secret" || r.FormValue("grant_type") != "client_credentials" {
+				rw.WriteHeader(http.StatusUnauthorized)
+				fmt.Fprint(rw, `{"error":"invalid_client"}`)
+				return
+			}
+			token := fmt.Sprintf("tok%d", atomic.AddInt32(&s.issued, 1))
+			s.mu.Lock()
+			s.valid = token
+			s.mu.Unlock()
+			fmt.Fprintf(rw, `{"access_token":%q,"token_type":"bearer","expires_in":3600}`, token)
+		default:
+			s.mu.Lock()
+			valid := s.valid
+			s.mu.Unlock()
+			if r.Header.Get("Authorization") != "Bearer "+valid {
+				rw.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			fmt.Fprint(rw, "ok")
+		}
+	}))
+	return s
+}
+
+func newAuthCollector(t *testing.T, s *tokenServer) (*Collector, *ClientCredentials) {
+	cc := &ClientCredentials{TokenURL: s.URL + "/token", ClientID: "id", ClientSecret: "secret"}
+	c := NewCollector()
+	if err := c.AddAuthenticator("*", cc); err != nil {
+		t.Fatal(err)
+	}
+	return c, cc
+}
+
+func TestClientCredentialsReauthenticateOnce(t *testing.T) {
+	s := newTokenServer(t)
+	defer s.Close()
+	c, cc := newAuthCollector(t, s)
+
+	//模擬已被伺服器撤銷的token
+	cc.token = "revoked"
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			req, _ := NewRequest(fmt.Sprintf("%s/api/%d", s.URL, i))
+			if _, err := c.Request(req); err != nil {
+				t.Error(err)
+			}
+		}(i)
+	}
+	wg.Wait()
+	if n := atomic.LoadInt32(&s.issued); n != 1 {
+		t.Fatalf("token fetched %d times, want 1", n)
+	}
+}
+
+func TestClientCredentialsRefreshBeforeExpiry(t *testing.T) {
+	s := newTokenServer(t)
+	defer s.Close()
+	c, cc := newAuthCollector(t, s)
+
+	get := func(path string) {
+		req, _ := NewRequest(s.URL + path)
+		if _, err := c.Request(req); err != nil {
+			t.Fatal(err)
+		}
+	}
+	get("/a")
+	get("/b")
+	if n := atomic.LoadInt32(&s.issued); n != 1 {
+		t.Fatalf("token fetched %d times, want 1", n)
+	}
+
+	//剩餘時間小於ExpiryDelta時 在請求前重新取得token
+	cc.mu.Lock()
+	cc.expiry = time.Now().Add(5 * time.Second)
+	cc.mu.Unlock()
+	get("/c")
+	if n := atomic.LoadInt32(&s.issued); n != 2 {
+		t.Fatalf("token fetched %d times, want 2", n)
+	}
+}
+
+func TestAddAuthenticatorNoPattern(t *testing.T) {
+	c := NewCollector()
+	if err := c.AddAuthenticator("", &BearerToken{Token: "t"}); err != ErrNoDomainPattern {
+		t.Fatalf("err = %v, want ErrNoDomainPattern", err)
+	}
+	//Limiter保留原本的錯誤 並可匹配ErrNoDomainPattern
+	err := c.AddLimit(&Limiter{})
+	if err != ErrlimiterNoParttern || !errors.Is(err, ErrNoDomainPattern) {
+		t.Fatalf("AddLimit err = %v, want ErrlimiterNoParttern wrapping ErrNoDomainPattern", err)
+	}
+	if err.Error() != "scrapingo: limiter cannt No Parttern" {
+		t.Fatalf("ErrlimiterNoParttern message changed: %q", err)
+	}
+}
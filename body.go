@@ -0,0 +1,157 @@
+package scrapingo
+
+import (
+	"bufio"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/andybalholm/brotli"
+	"golang.org/x/net/html/charset"
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/unicode"
+	"golang.org/x/text/transform"
+)
+
+//未設置Accept-Encoding時 請求默認所支持的壓縮格式
+const defaultAcceptEncoding = "gzip, deflate, br"
+
+//依照Content-Encoding解壓縮ResponsBody
+//解壓縮後會移除Content-Encoding以及Content-Length 不支持的格式將不進行解壓縮
+func decompress(resp *http.Response) (io.Reader, error) {
+	encoding := strings.ToLower(strings.TrimSpace(resp.Header.Get("Content-Encoding")))
+	switch encoding {
+	case "gzip", "x-gzip", "deflate", "br":
+	default:
+		return resp.Body, nil
+	}
+	resp.Header.Del("Content-Encoding")
+	resp.Header.Del("Content-Length")
+	resp.ContentLength = -1
+	resp.Uncompressed = true
+
+	//沒有Body的Response(HEAD 204 304等)不進行解壓縮
+	body := bufio.NewReader(resp.Body)
+	if _, err := body.Peek(1); err == io.EOF {
+		return body, nil
+	}
+	switch encoding {
+	case "gzip", "x-gzip":
+		gr, err := gzip.NewReader(body)
+		if err != nil {
+			return nil, err
+		}
+		return gr, nil
+	case "deflate":
+		return newDeflateReader(body), nil
+	}
+	return brotli.NewReader(body), nil
+}
+
+//deflate大多帶有zlib標頭 但仍有部分伺服器直接返回raw deflate
+func newDeflateReader(body io.Reader) io.Reader {
+	br := bufio.NewReader(body)
+	header, err := br.Peek(2)
+	if err == nil && header[0]&0x0f == 8 && (uint16(header[0])<<8|uint16(header[1]))%31 == 0 {
+		if zr, err := zlib.NewReader(br); err == nil {
+			return zr
+		}
+	}
+	return flate.NewReader(br)
+}
+
+//返回Content-Type的媒體類型(小寫 不包含參數)
+//未設置Content-Type時以ResponsBody進行判斷
+func (r *Response) ContentType() string {
+	ct := r.Header.Get("Content-Type")
+	if ct == "" {
+		ct = http.DetectContentType(r.RawBody)
+	}
+	mediatype, _, err := mime.ParseMediaType(ct)
+	if err != nil {
+		return strings.ToLower(strings.TrimSpace(strings.Split(ct, ";")[0]))
+	}
+	return mediatype
+}
+
+//判斷該媒體類型是否為文字內容
+func isTextual(mediatype string) bool {
+	switch {
+	case strings.HasPrefix(mediatype, "text/"),
+		strings.HasSuffix(mediatype, "+xml"),
+		strings.HasSuffix(mediatype, "+json"):
+		return true
+	}
+	switch mediatype {
+	case "application/json", "application/xml", "application/xhtml+xml",
+		"application/javascript", "application/x-javascript", "application/ecmascript",
+		"application/x-www-form-urlencoded":
+		return true
+	}
+	return false
+}
+
+//依照Content-Type對ResponsBody進行charset解碼
+//Request.Raw為true或是非文字內容時不進行解碼 Body與RawBody相同
+//指定了Request.Encoding或Collector.Encoding時使用所指定的編碼
+func (c *Collector) decodeBody(req *Request, resp *Response) error {
+	resp.Body = resp.RawBody
+	if req.Raw {
+		return nil
+	}
+
+	name := req.Encoding
+	if name == "" {
+		name = c.Encoding
+	}
+
+	var e encoding.Encoding
+	if name != "" {
+		if e, _ = charset.Lookup(name); e == nil {
+			return ErrUnknownEncoding
+		}
+	} else {
+		e = determinEncoding(resp)
+	}
+	if e == nil || e == encoding.Nop || e == unicode.UTF8 {
+		return nil
+	}
+
+	body, _, err := transform.Bytes(e.NewDecoder(), resp.RawBody)
+	if err != nil {
+		return err
+	}
+	resp.Body = body
+	return nil
+}
+
+//探測ResponsBody所使用的編碼方式 非文字內容時返回nil
+//JSON未指定charset時視為UTF-8
+//html則取前1024byte 配合Content-Type以及<meta>進行探測
+func determinEncoding(resp *Response) encoding.Encoding {
+	mediatype := resp.ContentType()
+	if !isTextual(mediatype) {
+		return nil
+	}
+
+	ct := resp.Header.Get("Content-Type")
+	if _, params, err := mime.ParseMediaType(ct); err == nil && params["charset"] != "" {
+		if e, _ := charset.Lookup(params["charset"]); e != nil {
+			return e
+		}
+	}
+	if mediatype == "application/json" || strings.HasSuffix(mediatype, "+json") {
+		return unicode.UTF8
+	}
+
+	raw := resp.RawBody
+	if len(raw) > 1024 {
+		raw = raw[:1024]
+	}
+	e, _, _ := charset.DetermineEncoding(raw, ct)
+	return e
+}
@@ -0,0 +1,233 @@
+package scrapingo
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+//Request.CacheStatus的值
+const (
+	//直接使用緩存中的Response 沒有進行請求
+	CacheHit = "HIT"
+	//緩存已過期 經由If-None-Match或If-Modified-Since確認後仍使用緩存
+	CacheRevalidated = "REVALIDATED"
+	//緩存中沒有可用的Response 已重新請求
+	CacheMiss = "MISS"
+)
+
+//以Request.Fingerprint為Key 將Response儲存至磁碟的緩存
+//只緩存GET以及HEAD請求 且StatusCode被Collector接受的Response
+type DiskCache struct {
+	//緩存所在的目錄
+	Dir string
+	//Respons未提供Cache-Control或Expires時的有效時間
+	//為0時每次都需重新確認
+	TTL time.Duration
+}
+
+//傳入目錄以及默認的有效時間初始化DiskCache
+func NewDiskCache(dir string, ttl time.Duration) *DiskCache {
+	return &DiskCache{Dir: dir, TTL: ttl}
+}
+
+//緩存中Response的相關資訊 Body另外儲存
+type cacheEntry struct {
+	StatusCode int         `json:"statusCode"`
+	Header     http.Header `json:"header"`
+	URL        string      `json:"url"`
+	StoredAt   time.Time   `json:"storedAt"`
+	Expires    time.Time   `json:"expires"`
+}
+
+//判斷緩存是否仍在有效時間內
+func (e *cacheEntry) fresh(now time.Time) bool {
+	return now.Before(e.Expires)
+}
+
+//將緩存轉為Response
+func (e *cacheEntry) response(raw []byte) (*Response, error) {
+	u, err := url.Parse(e.URL)
+	if err != nil {
+		return nil, err
+	}
+	return newResponse(e.StatusCode, e.Header, u, raw), nil
+}
+
+//為請求加上緩存的ETag以及Last-Modified 進行條件請求
+func (e *cacheEntry) setValidators(h http.Header) {
+	if etag := e.Header.Get("ETag"); etag != "" {
+		h.Set("If-None-Match", etag)
+	}
+	if modified := e.Header.Get("Last-Modified"); modified != "" {
+		h.Set("If-Modified-Since", modified)
+	}
+}
+
+//是否帶有可用於條件請求的ETag或Last-Modified
+func (e *cacheEntry) hasValidators() bool {
+	return e.Header.Get("ETag") != "" || e.Header.Get("Last-Modified") != ""
+}
+
+//緩存所對應的檔案路徑 不包含副檔名
+func (d *DiskCache) path(key uint64) string {
+	name := fmt.Sprintf("%016x", key)
+	return filepath.Join(d.Dir, name[:2], name)
+}
+
+//讀取緩存 不存在時返回error
+func (d *DiskCache) load(key uint64) (*cacheEntry, []byte, error) {
+	p := d.path(key)
+	meta, err := ioutil.ReadFile(p + ".json")
+	if err != nil {
+		return nil, nil, err
+	}
+	entry := &cacheEntry{}
+	if err = json.Unmarshal(meta, entry); err != nil {
+		return nil, nil, err
+	}
+	raw, err := ioutil.ReadFile(p + ".body")
+	if err != nil {
+		return nil, nil, err
+	}
+	return entry, raw, nil
+}
+
+//儲存緩存 先寫入暫存檔後再重新命名 避免讀取到寫入一半的緩存
+func (d *DiskCache) store(key uint64, entry *cacheEntry, raw []byte) error {
+	p := d.path(key)
+	if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+		return err
+	}
+	meta, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	if err = writeFileAtomic(p+".body", raw); err != nil {
+		return err
+	}
+	return writeFileAtomic(p+".json", meta)
+}
+
+//刪除緩存
+func (d *DiskCache) remove(key uint64) {
+	p := d.path(key)
+	os.Remove(p + ".json")
+	os.Remove(p + ".body")
+}
+
+func (d *DiskCache) String() string {
+	return fmt.Sprintf("Dir:%s TTL:%.3fs", d.Dir, d.TTL.Seconds())
+}
+
+func writeFileAtomic(name string, data []byte) error {
+	f, err := ioutil.TempFile(filepath.Dir(name), filepath.Base(name)+".tmp*")
+	if err != nil {
+		return err
+	}
+	if _, err = f.Write(data); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return err
+	}
+	if err = f.Close(); err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+	return os.Rename(f.Name(), name)
+}
+
+//依照Cache-Control以及Expires計算緩存的過期時間
+//Cache-Control為no-store時返回false 表示不可緩存
+func (d *DiskCache) expires(h http.Header, now time.Time) (time.Time, bool) {
+	for _, directive := range strings.Split(h.Get("Cache-Control"), ",") {
+		directive = strings.ToLower(strings.TrimSpace(directive))
+		switch {
+		case directive == "no-store":
+			return time.Time{}, false
+		case directive == "no-cache":
+			return now, true
+		case strings.HasPrefix(directive, "max-age="):
+			if sec, err := strconv.Atoi(strings.TrimPrefix(directive, "max-age=")); err == nil {
+				return now.Add(time.Duration(sec) * time.Second), true
+			}
+		}
+	}
+	if v := h.Get("Expires"); v != "" {
+		if t, err := http.ParseTime(v); err == nil {
+			return t, true
+		}
+		return now, true
+	}
+	return now.Add(d.TTL), true
+}
+
+//只緩存GET以及HEAD請求
+func cacheableMethod(method string) bool {
+	return method == http.MethodGet || method == http.MethodHead
+}
+
+//優先使用緩存中的Response
+//緩存過期且帶有ETag或Last-Modified時進行條件請求 返回304時繼續使用緩存
+//結果會記錄至Request.CacheStatus
+func (t *Transfer) cacheDo(r *Request, req *http.Request, MaxBodySize int) (*Response, error) {
+	entry, raw, err := t.Cache.load(r.Fingerprint)
+	if err != nil {
+		entry = nil
+	}
+
+	if entry != nil {
+		if entry.fresh(time.Now()) {
+			if resp, err := entry.response(raw); err == nil {
+				r.CacheStatus = CacheHit
+				return resp, nil
+			}
+		}
+		if entry.hasValidators() {
+			req = req.Clone(req.Context())
+			entry.setValidators(req.Header)
+		}
+	}
+
+	resp, err := t.retryDo(r, req, MaxBodySize)
+
+	if entry != nil && resp != nil && resp.StatusCode == http.StatusNotModified {
+		now := time.Now()
+		for key, val := range resp.Header {
+			if key != "Content-Length" {
+				entry.Header[key] = val
+			}
+		}
+		entry.StoredAt = now
+		entry.Expires, _ = t.Cache.expires(entry.Header, now)
+		t.Cache.store(r.Fingerprint, entry, raw)
+
+		r.CacheStatus = CacheRevalidated
+		return entry.response(raw)
+	}
+	if err != nil {
+		return resp, err
+	}
+
+	r.CacheStatus = CacheMiss
+	now := time.Now()
+	if expires, ok := t.Cache.expires(resp.Header, now); ok {
+		t.Cache.store(r.Fingerprint, &cacheEntry{
+			StatusCode: resp.StatusCode,
+			Header:     resp.Header,
+			URL:        resp.URL.String(),
+			StoredAt:   now,
+			Expires:    expires,
+		}, resp.RawBody)
+	} else if entry != nil {
+		t.Cache.remove(r.Fingerprint)
+	}
+	return resp, nil
+}
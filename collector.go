@@ -3,13 +3,14 @@ package scrapingo
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
-	"hash/fnv"
 	"io"
 	"io/ioutil"
 	"net/http"
 	"net/url"
 	"reflect"
+	"runtime/debug"
 	"strings"
 	"sync"
 	"sync/atomic"
@@ -58,8 +59,12 @@ func ResultLogKey(f func(*ParseResult) logger.LogKey) CollectorOption {
 
 var (
 	//Collector默認所使用的ErrLogKey
-	DefaultErrLogKey = func(_ *Request, e error) logger.LogKey {
-		return logger.LogKey{"errMsg": e.Error()}
+	DefaultErrLogKey = func(r *Request, e error) logger.LogKey {
+		key := logger.LogKey{"errMsg": e.Error(), "attempt": r.Attempt}
+		if r.Proxy != nil {
+			key["proxy"] = r.Proxy.Redacted()
+		}
+		return key
 	}
 	//Collector默認所使用的ReqLogKey
 	DefaultReqLogKey = func(*Request) logger.LogKey {
@@ -67,10 +72,17 @@ var (
 	}
 	//Collector默認所使用的ResultLogKey
 	DefaultResultLogKey = func(p *ParseResult) logger.LogKey {
-		return logger.LogKey{
+		key := logger.LogKey{
 			"requestCount": len(p.Requests),
 			"itmesCount":   len(p.Items),
 		}
+		if p.ParentRequest.Proxy != nil {
+			key["proxy"] = p.ParentRequest.Proxy.Redacted()
+		}
+		if p.ParentRequest.CacheStatus != "" {
+			key["cache"] = p.ParentRequest.CacheStatus
+		}
+		return key
 	}
 )
 
@@ -88,6 +100,62 @@ func MaxDepth(d int) CollectorOption {
 	}
 }
 
+//只允許訪問所列出的域名(包含子域名)
+func AllowedDomains(domains ...string) CollectorOption {
+	return func(c *Collector) {
+		c.AllowedDomains = domains
+	}
+}
+
+//禁止訪問所列出的域名(包含子域名) 優先於AllowedDomains
+func DisallowedDomains(domains ...string) CollectorOption {
+	return func(c *Collector) {
+		c.DisallowedDomains = domains
+	}
+}
+
+//只允許訪問符合任一個URLFilter的URL
+//(參考scrapingo.RegexpFilter scrapingo.GlobFilter)
+func URLFilters(filters ...URLFilter) CollectorOption {
+	return func(c *Collector) {
+		c.URLFilters = filters
+	}
+}
+
+//禁止訪問符合任一個URLFilter的URL 優先於URLFilters
+func DisallowedURLFilters(filters ...URLFilter) CollectorOption {
+	return func(c *Collector) {
+		c.DisallowedURLFilters = filters
+	}
+}
+
+//修改Collector默認的URLNormalizer 傳入nil時不對URL進行格式化
+//(參考scrapingo.CanonicalNormalizer)
+func Normalizer(n URLNormalizer) CollectorOption {
+	return func(c *Collector) {
+		c.normalizer = n
+	}
+}
+
+//修改Collector默認的Fingerprinter 傳入nil時使用默認值
+//(參考scrapingo.DefaultFingerprinter)
+func Fingerprints(f Fingerprinter) CollectorOption {
+	return func(c *Collector) {
+		if f == nil {
+			f = NewFingerprinter()
+		}
+		c.fingerprinter = f
+	}
+}
+
+//指定所有ResponsBody的編碼 Request.Encoding優先
+//Collector默認依照Content-Type進行探測
+func ForceEncoding(name string) CollectorOption {
+	return func(c *Collector) {
+		c.Encoding = name
+	}
+}
+
 //修改Collector的默認UserAgent
 func UserAgent(s string) CollectorOption {
 	return func(c *Collector) {
@@ -102,6 +170,64 @@ func RequestTimeOut(t time.Duration) CollectorOption {
 	}
 }
 
+//修改Collector的默認重試策略 傳入nil時不進行重試
+//(參考scrapingo.RetryPolicy)
+func Retry(p *RetryPolicy) CollectorOption {
+	return func(c *Collector) {
+		c.transfer.Retry = p
+	}
+}
+
+//修改Collector默認接受的StatusCode 默認只接受200
+//不被接受的StatusCode會返回*HTTPStatusError
+func AcceptStatus(codes ...int) CollectorOption {
+	return func(c *Collector) {
+		c.transfer.AcceptStatus = codes
+	}
+}
+
+//設置Collector所使用的CookieStorage 傳入nil時則初始化新的CookieStorage
+//Collector默認不保存Cookie
+func CookieJar(s *CookieStorage) CollectorOption {
+	return func(c *Collector) {
+		if s == nil {
+			s = NewCookieStorage()
+		}
+		c.cookies = s
+		c.transfer.Client.Jar = s
+	}
+}
+
+//設置Collector請求時所使用的ProxySwitcher
+//Client.Transport不為*http.Transport時 請求會返回ErrUnsupportedTransport
+//(參考scrapingo.ProxyPool以及Collector.SetProxies)
+func Proxies(p ProxySwitcher) CollectorOption {
+	return func(c *Collector) {
+		c.transfer.proxyErr = c.SetProxies(p)
+	}
+}
+
+//為true時Collector會遵守robots.txt 不訪問被Disallow的URL
+//robots.txt設置了Crawl-delay時會自動為該Host註冊Limiter
+//Collector默認不讀取robots.txt
+func RobotsTxt(b bool) CollectorOption {
+	return func(c *Collector) {
+		c.robots = nil
+		if b {
+			c.robots = newRobotsCache()
+		}
+	}
+}
+
+//設置Collector的磁碟緩存 dir為緩存所在的目錄
+//ttl為Respons未提供Cache-Control或Expires時的有效時間
+//(參考scrapingo.DiskCache)
+func CacheDir(dir string, ttl time.Duration) CollectorOption {
+	return func(c *Collector) {
+		c.transfer.Cache = NewDiskCache(dir, ttl)
+	}
+}
+
 //修改Collector的默認的URL去重儲存
 func VisitedStorage(v VisitStorage) CollectorOption {
 	return func(c *Collector) {
@@ -125,6 +251,27 @@ type Collector struct {
 
 	MaxBodySize int
 
+	//允許訪問的域名(包含子域名) 為空時不進行限制
+
+	AllowedDomains []string
+
+	//禁止訪問的域名(包含子域名) 優先於AllowedDomains
+
+	DisallowedDomains []string
+
+	//URL需符合其中任一個URLFilter才會進行訪問 為空時不進行限制
+
+	URLFilters []URLFilter
+
+	//符合其中任一個URLFilter的URL將不進行訪問 優先於URLFilters
+
+	DisallowedURLFilters []URLFilter
+
+	//指定ResponsBody的編碼 為空時依照Content-Type進行探測
+	//Request設置了Encoding時以Request為主
+
+	Encoding string
+
 	//當爬取URL或是Engine的Saveitem函數發生錯誤時會調用自定義的ErrCallback函數
 	//調用OnErr即可自行添加
 
@@ -140,6 +287,26 @@ type Collector struct {
 
 	resultcallbacks []ResultCallbackContainer
 
+	//當Response為HTML時 會對符合CSS選擇器的元素調用自定義的HTMLCallback函數
+	//調用OnHTML即可自行添加
+
+	htmlcallbacks []HTMLCallbackContainer
+
+	//當Response為HTML或XML時 會對符合XPath的節點調用自定義的XMLCallback函數
+	//調用OnXML即可自行添加
+
+	xmlcallbacks []XMLCallbackContainer
+
+	//從HTML頁面中取出連結 結果會加入至ParseResult.Requests
+	//調用AddLinkExtractor即可自行添加
+
+	linkextractors []*LinkExtractor
+
+	//需要登入的網站 請求前會先進行登入 Session過期時會重新登入
+	//調用AddSession即可自行添加
+
+	sessions []*Session
+
 	//LoggerMode為true時會輸出Log
 	//Collector默認開啟Logger
 
@@ -179,6 +346,26 @@ type Collector struct {
 
 	visitedStorage VisitStorage
 
+	//去重以及請求前對URL進行格式化
+	//Collector默認使用移除utm_*參數的CanonicalNormalizer
+
+	normalizer URLNormalizer
+
+	//計算去重所使用的請求指紋
+	//Collector默認使用不包含任何Header的DefaultFingerprinter
+
+	fingerprinter Fingerprinter
+
+	//保存Respons所設置的Cookie 並在請求時自動帶上
+	//調用CollectorOption CookieJar進行設置 默認為nil
+
+	cookies *CookieStorage
+
+	//緩存每個Host的robots.txt 為nil時不遵守robots.txt
+	//調用CollectorOption RobotsTxt進行設置
+
+	robots *robotsCache
+
 	transfer *Transfer
 	mu       *sync.Mutex
 	ctx      context.Context
@@ -230,6 +417,8 @@ func (c *Collector) defualtParms() {
 	c.LoggerMode = true
 	c.logger = logger.DefaultLogger()
 	c.visitedStorage = defaultHasStorage()
+	c.normalizer = defaultNormalizer()
+	c.fingerprinter = NewFingerprinter()
 	c.requestlogkey = DefaultReqLogKey
 	c.errlogkey = DefaultErrLogKey
 	c.resultlogkey = DefaultResultLogKey
@@ -248,29 +437,48 @@ func (c *Collector) setItemId() int64 {
 }
 
 //傳入Request進行爬取
+//爬取時會複製一份Request 不會修改傳入的Request
 func (c *Collector) Request(req *Request) (*ParseResult, error) {
-	return c.scraping(req.URL.String(), req.Header, req.Method, req.Depth+1, req.Body, context.Background(), req.Parse)
+	r := req.copy()
+	r.Depth = req.Depth + 1
+	return c.scraping(r)
 }
 
 //傳入所需的參數進行爬取
 func (c *Collector) Do(URL, Method string, Header http.Header, Body io.Reader, ctx context.Context, p ParseFunc) (*ParseResult, error) {
-	return c.scraping(URL, Header, Method, 1, Body, ctx, p)
+	return c.scrapingURL(URL, Header, Method, Body, ctx, p)
 }
 
 //傳入URL以及對應的解析（ParseFunc）進行爬取
 func (c *Collector) Get(URL string, p ParseFunc) (*ParseResult, error) {
-	return c.scraping(URL, http.Header{}, http.MethodGet, 1, nil, nil, p)
+	return c.scrapingURL(URL, http.Header{}, http.MethodGet, nil, nil, p)
 }
 
-// Content-Type標頭設置为application / x-www-form-urlencoded。
+// Content-Type標頭設置为application/x-www-form-urlencoded。
 //要設置其他自定義標頭，請使用Do or Request。
 func (c *Collector) PostForm(URL string, data map[string]string, p ParseFunc) (*ParseResult, error) {
-	return c.Post(URL, "application / x-www-form-urlencoded", createDataReader(data), p)
+	return c.Post(URL, formURLEncoded, createDataReader(data), p)
 }
 
 //要設置其他自定義標頭，請使用Do or Request。
 func (c *Collector) Post(URL string, contentType string, Body io.Reader, p ParseFunc) (*ParseResult, error) {
-	return c.scraping(URL, http.Header{"Content-Type": {contentType}}, http.MethodPost, 1, nil, nil, p)
+	return c.scrapingURL(URL, http.Header{"Content-Type": {contentType}}, http.MethodPost, Body, nil, p)
+}
+
+//下載URL並將ResponsBody直接寫入檔案
+//進度以及校驗等設置請參考scrapingo.Download
+func (c *Collector) Download(URL string, d *Download) (*ParseResult, error) {
+	u, err := url.Parse(URL)
+	if err != nil {
+		return nil, err
+	}
+	return c.scraping(&Request{
+		URL:      u,
+		Header:   http.Header{},
+		Method:   http.MethodGet,
+		Depth:    1,
+		Download: d,
+	})
 }
 
 //Id為刪除時的唯一標示 設置ErrCallback
@@ -367,20 +575,35 @@ func (c *Collector) handleOnResult(r *ParseResult) {
 	}
 }
 
-//爬取所指定的URL 並使用傳入的ParseFunc進行相對應的解析
-//會調用所指定的Callback函數
-func (c *Collector) scraping(u string, Header http.Header, Method string, Depth int, Body io.Reader, ctx context.Context, p ParseFunc) (*ParseResult, error) {
-	req, err := c.checkRequsetInfo(u, Header, Method, Body, Depth, ctx, p)
-
+//將傳入的參數轉為深度為1的Request後進行爬取
+func (c *Collector) scrapingURL(u string, Header http.Header, Method string, Body io.Reader, ctx context.Context, p ParseFunc) (*ParseResult, error) {
+	URL, err := url.Parse(u)
 	if err != nil {
 		return nil, err
 	}
+	return c.scraping(&Request{
+		URL:    URL,
+		Ctx:    ctx,
+		Header: Header,
+		Method: Method,
+		Body:   Body,
+		Depth:  1,
+		Parse:  p,
+	})
+}
+
+//爬取Request所指定的URL 並使用Request的解析函式進行相對應的解析
+//會調用所指定的Callback函數
+func (c *Collector) scraping(req *Request) (*ParseResult, error) {
+	if err := c.checkRequsetInfo(req); err != nil {
+		return nil, err
+	}
 
 	c.handleOnRequest(req)
 
-	rc, ok := Body.(io.ReadCloser)
-	if !ok && Body != nil {
-		rc = ioutil.NopCloser(Body)
+	var rc io.ReadCloser
+	if req.Body != nil {
+		rc = ioutil.NopCloser(req.Body)
 	}
 
 	httpReq := &http.Request{
@@ -389,21 +612,33 @@ func (c *Collector) scraping(u string, Header http.Header, Method string, Depth
 		Proto:      "HTTP/1.1",
 		ProtoMajor: 1,
 		ProtoMinor: 1,
-		Header:     req.Header,
+		Header:     req.Header.Clone(),
 		Body:       rc,
 		Host:       removeEmptyPort(req.URL.Host),
 	}
 
-	setRequsetBody(httpReq, Body)
-	httpReq = httpReq.WithContext(ctx)
+	setRequsetBody(httpReq, req.Body)
+	httpReq = httpReq.WithContext(req.Ctx)
+
+	parse := req.parseFunc()
 
-	respbody, err := c.transfer.do(httpReq, c.MaxBodySize)
+	resp, err := c.sessionFetch(req, httpReq)
 	if err != nil {
 		c.handleOnErr(req, err)
-		return nil, err
+
+		//StatusCode不被接受時 若Request設置了ErrPageParse則仍對錯誤頁面進行解析
+		var statusErr *HTTPStatusError
+		if resp == nil || req.ErrPageParse == nil || !errors.As(err, &statusErr) {
+			return nil, err
+		}
+		parse = req.ErrPageParse.ToParseWithError()
 	}
 
-	ParseResult := req.Parse(respbody)
+	ParseResult, err := c.callParse(parse, resp)
+	if err != nil {
+		c.handleOnErr(req, err)
+		return nil, err
+	}
 	ParseResult.ParentRequest = req
 
 	c.setResultInfo(ParseResult)
@@ -413,11 +648,48 @@ func (c *Collector) scraping(u string, Header http.Header, Method string, Depth
 	return ParseResult, nil
 }
 
+//進行請求並對ResponsBody進行charset解碼
+//StatusCode不被接受時同時返回Response以及*HTTPStatusError
+func (c *Collector) fetch(req *Request, httpReq *http.Request) (*Response, error) {
+	resp, err := c.transfer.do(req, httpReq, c.MaxBodySize)
+	if resp == nil {
+		return nil, err
+	}
+	resp.Request = req
+
+	if decodeErr := c.decodeBody(req, resp); decodeErr != nil {
+		return nil, decodeErr
+	}
+	return resp, err
+}
+
+//調用解析函式以及HTMLCallback XMLCallback 發生panic時轉為*ParsePanicError
+//解析函式返回nil時視為空的ParseResult
+func (c *Collector) callParse(parse ParseFuncWithError, resp *Response) (result *ParseResult, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			result, err = nil, &ParsePanicError{Value: r, Stack: debug.Stack(), URL: resp.URL}
+		}
+	}()
+	if result, err = parse(resp); err != nil {
+		return nil, err
+	}
+	if result == nil {
+		result = &ParseResult{}
+	}
+	if err = c.handleOnMarkup(resp, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
 //當item有中有設置scrapingo.Model 或者是 相關參數時
 //將賦予參數所對應的值  （請查看scrapingo.Model的說明）
+//ParseResult.Requests會繼承ParentRequest的Meta 已設置的值優先
 func (c *Collector) setResultInfo(result *ParseResult) {
 	for _, req := range result.Requests {
 		req.URL, _ = result.ParentRequest.URL.Parse(req.URL.String())
+		req.Meta = inheritMeta(result.ParentRequest.Meta, req.Meta)
 	}
 	for _, item := range result.Items {
 		itemID := c.setItemId()
@@ -450,64 +722,77 @@ func (c *Collector) setResultInfo(result *ParseResult) {
 	}
 }
 
-//確認請求內容 當產生錯誤時將不進行請求
+//確認請求內容並補上默認值 當產生錯誤時將不進行請求
 //也不會調用Callback函數
-func (c *Collector) checkRequsetInfo(u string, Header http.Header, Method string, Body io.Reader, Depth int, ctx context.Context, p ParseFunc) (*Request, error) {
-	URL, err := url.Parse(u)
-	if err != nil {
-		return nil, err
-	}
-	if u == "" {
-		return nil, ErrURLMiss
+func (c *Collector) checkRequsetInfo(req *Request) error {
+	if req.URL == nil || req.URL.String() == "" {
+		return ErrURLMiss
+	}
+	if c.normalizer != nil {
+		u, err := c.normalizer.Normalize(req.URL)
+		if err != nil {
+			return err
+		}
+		req.URL = u
 	}
 
-	if c.MaxDepth > 0 && Depth > c.MaxDepth {
-		return nil, fmt.Errorf("scrapingo: RequestDepth is %d ,Over MaxDepth %d", Depth, c.MaxDepth)
+	if c.MaxDepth > 0 && req.Depth > c.MaxDepth {
+		return fmt.Errorf("scrapingo: RequestDepth is %d ,Over MaxDepth %d", req.Depth, c.MaxDepth)
 	}
-	if ctx == nil {
-		ctx = c.ctx
+	if err := c.checkFilters(req.URL); err != nil {
+		return err
 	}
-	if Method == "" {
-		Method = http.MethodGet
+	if req.Ctx == nil {
+		req.Ctx = c.ctx
+	}
+	if req.Method == "" {
+		req.Method = http.MethodGet
+	}
+	if req.Header == nil {
+		req.Header = http.Header{}
 	}
 
-	var hascode uint64
-	f := fnv.New64a()
-
-	f.Write([]byte(URL.String()))
+	if c.robots != nil {
+		if err := c.checkRobots(req); err != nil {
+			return err
+		}
+	}
 
-	if Method == http.MethodGet {
-		hascode = f.Sum64()
-	} else if Body != nil {
-		bytes := readertobyte(Body)
-		f.Write(bytes)
-		hascode = f.Sum64()
+	//將Body轉為可重複讀取的bytes.Reader 重試時才能重新發送
+	if req.Body != nil {
+		req.Body = bytes.NewReader(readertobyte(req.Body))
 	}
 
-	if c.isVisitd(hascode) {
-		return nil, ErrIsVisitedURL
+	hascode, err := c.fingerprinter.Fingerprint(req)
+	if err != nil {
+		return err
+	}
+	//DontFilter為true時不進行去重
+	if !req.DontFilter {
+		if c.isVisitd(hascode) {
+			return ErrIsVisitedURL
+		}
+		c.Visited(hascode)
 	}
-	c.Visited(hascode)
+	req.Fingerprint = hascode
 
-	if Header.Get("User-Agent") == "" {
-		Header.Add("User-Agent", c.UserAgent)
+	if req.Header.Get("User-Agent") == "" {
+		req.Header.Add("User-Agent", c.UserAgent)
 	}
-	if Method == http.MethodPost && Header.Get("Content-Type") == "" {
-		Header.Add("Content-Type", "application / x-www-form-urlencoded")
+	if req.Header.Get("Accept-Encoding") == "" {
+		if req.Download != nil {
+			//下載時不進行壓縮 Range才能對應到檔案的位置
+			req.Header.Set("Accept-Encoding", "identity")
+		} else {
+			req.Header.Set("Accept-Encoding", defaultAcceptEncoding)
+		}
 	}
-	if p == nil {
-		p = NilParse
+	if req.Method == http.MethodPost && req.Header.Get("Content-Type") == "" {
+		req.Header.Add("Content-Type", formURLEncoded)
 	}
-	return &Request{
-		ID:     c.setRequestId(),
-		URL:    URL,
-		Ctx:    ctx,
-		Header: Header,
-		Method: Method,
-		Body:   Body,
-		Depth:  Depth,
-		Parse:  p,
-	}, nil
+	req.Attempt, req.Proxy, req.CacheStatus = 0, nil, ""
+	req.ID = c.setRequestId()
+	return nil
 }
 func removeEmptyPort(host string) string {
 	if strings.LastIndex(host, ":") > strings.LastIndex(host, "]") {
@@ -578,6 +863,49 @@ func createDataReader(data map[string]string) io.Reader {
 	return strings.NewReader(val.Encode())
 }
 
+//返回Collector所使用的CookieStorage 未設置時返回nil
+func (c *Collector) CookieStorage() *CookieStorage {
+	return c.cookies
+}
+
+//返回請求該URL時會帶上的Cookie
+func (c *Collector) Cookies(URL string) []*http.Cookie {
+	if c.cookies == nil {
+		return nil
+	}
+	u, err := url.Parse(URL)
+	if err != nil {
+		return nil
+	}
+	return c.cookies.Cookies(u)
+}
+
+//設置該URL所對應的Cookie 未設置CookieStorage時返回ErrNoCookieJar
+func (c *Collector) SetCookies(URL string, cookies []*http.Cookie) error {
+	if c.cookies == nil {
+		return ErrNoCookieJar
+	}
+	u, err := url.Parse(URL)
+	if err != nil {
+		return err
+	}
+	c.cookies.SetCookies(u, cookies)
+	return nil
+}
+
+//清除該域名(包含子域名)的Cookie domain為空時清除全部
+func (c *Collector) ClearCookies(domain string) {
+	if c.cookies != nil {
+		c.cookies.Clear(domain)
+	}
+}
+
+//設置請求時所使用的ProxySwitcher 傳入nil時不使用Proxy
+//Client.Transport不為*http.Transport時返回ErrUnsupportedTransport
+func (c *Collector) SetProxies(p ProxySwitcher) error {
+	return c.transfer.SetProxySwitcher(p)
+}
+
 //添加對請求時對URL的限制
 func (c *Collector) AddLimit(l *Limiter) error {
 	return c.transfer.AddLimiter(l)
@@ -596,21 +924,36 @@ func (c *Collector) Close() {
 //Clone後的callback函式需重新定義
 func (c *Collector) Clone() *Collector {
 	return &Collector{
-		UserAgent:        c.UserAgent,
-		MaxDepth:         c.MaxDepth,
-		MaxBodySize:      c.MaxBodySize,
-		requestcount:     c.requestcount,
-		itemcount:        c.itemcount,
-		mu:               c.mu,
-		transfer:         c.transfer,
-		logger:           c.logger,
-		LoggerMode:       c.LoggerMode,
-		errcallbacks:     make([]ErrCallbackContainer, 0),
-		requestcallbacks: make([]RequestCallbackContainer, 0),
-		resultcallbacks:  make([]ResultCallbackContainer, 0),
-		errlogkey:        c.errlogkey,
-		requestlogkey:    c.requestlogkey,
-		resultlogkey:     c.resultlogkey,
+		UserAgent:            c.UserAgent,
+		MaxDepth:             c.MaxDepth,
+		MaxBodySize:          c.MaxBodySize,
+		AllowedDomains:       c.AllowedDomains,
+		DisallowedDomains:    c.DisallowedDomains,
+		URLFilters:           c.URLFilters,
+		DisallowedURLFilters: c.DisallowedURLFilters,
+		Encoding:             c.Encoding,
+		requestcount:         c.requestcount,
+		itemcount:            c.itemcount,
+		visitedStorage:       c.visitedStorage,
+		mu:                   c.mu,
+		transfer:             c.transfer,
+		cookies:              c.cookies,
+		robots:               c.robots,
+		normalizer:           c.normalizer,
+		fingerprinter:        c.fingerprinter,
+		logger:               c.logger,
+		LoggerMode:           c.LoggerMode,
+		errcallbacks:         make([]ErrCallbackContainer, 0),
+		requestcallbacks:     make([]RequestCallbackContainer, 0),
+		resultcallbacks:      make([]ResultCallbackContainer, 0),
+		htmlcallbacks:        make([]HTMLCallbackContainer, 0),
+		xmlcallbacks:         make([]XMLCallbackContainer, 0),
+		linkextractors:       c.linkextractors,
+		sessions:             c.sessions,
+		errlogkey:            c.errlogkey,
+		requestlogkey:        c.requestlogkey,
+		resultlogkey:         c.resultlogkey,
+		ctx:                  c.ctx,
 	}
 }
 
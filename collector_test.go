@@ -0,0 +1,29 @@
+package scrapingo
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCloneVisit(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(rw, "ok")
+	}))
+	defer srv.Close()
+
+	c := NewCollector()
+	clone := c.Clone()
+	req, _ := NewRequest(srv.URL + "/page")
+	if _, err := clone.Request(req); err != nil {
+		t.Fatal(err)
+	}
+	//Clone與原本的Collector共用去重儲存
+	if _, err := c.Request(req); err != ErrIsVisitedURL {
+		t.Fatalf("err = %v, want ErrIsVisitedURL", err)
+	}
+	if _, err := clone.Request(req); err != ErrIsVisitedURL {
+		t.Fatalf("clone err = %v, want ErrIsVisitedURL", err)
+	}
+}
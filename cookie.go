@@ -0,0 +1,332 @@
+package scrapingo
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/net/publicsuffix"
+)
+
+//Netscape cookies.txt中HttpOnly Cookie的前綴
+const netscapeHttpOnlyPrefix = "#HttpOnly_"
+
+//可序列化的Cookie 用於CookieStorage的匯出以及匯入
+//Expires為零值時表示Session Cookie
+//HostOnly為true時 Cookie只會發送至Domain本身 不包含子域名
+type Cookie struct {
+	Name     string    `json:"name"`
+	Value    string    `json:"value"`
+	Domain   string    `json:"domain"`
+	Path     string    `json:"path"`
+	Expires  time.Time `json:"expires"`
+	Secure   bool      `json:"secure"`
+	HttpOnly bool      `json:"httpOnly"`
+	HostOnly bool      `json:"hostOnly"`
+}
+
+//判斷Cookie是否已過期
+func (c *Cookie) expired(now time.Time) bool {
+	return !c.Expires.IsZero() && !c.Expires.After(now)
+}
+
+//判斷Cookie是否屬於該域名(包含子域名)
+func (c *Cookie) inDomain(domain string) bool {
+	return c.Domain == domain || strings.HasSuffix(c.Domain, "."+domain)
+}
+
+//轉為http.Cookie以及對應的URL 用於寫入cookiejar.Jar
+func (c *Cookie) httpCookie() (*url.URL, *http.Cookie) {
+	u := &url.URL{Scheme: "http", Host: c.Domain, Path: c.Path}
+	if c.Secure {
+		u.Scheme = "https"
+	}
+	hc := &http.Cookie{
+		Name:     c.Name,
+		Value:    c.Value,
+		Path:     c.Path,
+		Expires:  c.Expires,
+		Secure:   c.Secure,
+		HttpOnly: c.HttpOnly,
+	}
+	if !c.HostOnly {
+		hc.Domain = c.Domain
+	}
+	return u, hc
+}
+
+//實現http.CookieJar interface 並以publicsuffix區隔每個域名的Cookie
+//會另外記錄每個Cookie的完整屬性 使Cookie能夠匯出 匯入以及清除
+//配合CollectorOption CookieJar使用 即可在重啟後沿用登入狀態
+type CookieStorage struct {
+	mu  sync.Mutex
+	jar *cookiejar.Jar
+	//key為 domain;path;name
+	entries map[string]*Cookie
+}
+
+//初始化CookieStorage
+func NewCookieStorage() *CookieStorage {
+	s := &CookieStorage{}
+	s.reset()
+	return s
+}
+
+//清空所有的Cookie
+func (s *CookieStorage) reset() {
+	//publicsuffix.List不會返回錯誤
+	s.jar, _ = cookiejar.New(&cookiejar.Options{PublicSuffixList: publicsuffix.List})
+	s.entries = make(map[string]*Cookie)
+}
+
+//實現http.CookieJar interface的 SetCookies(*url.URL, []*http.Cookie)
+func (s *CookieStorage) SetCookies(u *url.URL, cookies []*http.Cookie) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.jar.SetCookies(u, cookies)
+
+	now := time.Now()
+	for _, hc := range cookies {
+		c, ok := newCookie(u, hc, now)
+		if !ok {
+			continue
+		}
+		key := strings.Join([]string{c.Domain, c.Path, c.Name}, ";")
+		if c.expired(now) {
+			delete(s.entries, key)
+			continue
+		}
+		s.entries[key] = c
+	}
+}
+
+//實現http.CookieJar interface的 Cookies(*url.URL) []*http.Cookie
+func (s *CookieStorage) Cookies(u *url.URL) []*http.Cookie {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.jar.Cookies(u)
+}
+
+//返回所有未過期的Cookie
+func (s *CookieStorage) All() []*Cookie {
+	return s.DomainCookies("")
+}
+
+//返回該域名(包含子域名)所有未過期的Cookie domain為空時返回全部
+//返回的Cookie依照Domain Path Name排序
+func (s *CookieStorage) DomainCookies(domain string) []*Cookie {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	domain = strings.TrimPrefix(strings.ToLower(domain), ".")
+	now := time.Now()
+	var cookies []*Cookie
+	for _, c := range s.entries {
+		if c.expired(now) || (domain != "" && !c.inDomain(domain)) {
+			continue
+		}
+		cc := *c
+		cookies = append(cookies, &cc)
+	}
+	sort.Slice(cookies, func(i, j int) bool {
+		a, b := cookies[i], cookies[j]
+		if a.Domain != b.Domain {
+			return a.Domain < b.Domain
+		}
+		if a.Path != b.Path {
+			return a.Path < b.Path
+		}
+		return a.Name < b.Name
+	})
+	return cookies
+}
+
+//清除該域名(包含子域名)的Cookie domain為空時清除全部
+func (s *CookieStorage) Clear(domain string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if domain == "" {
+		s.reset()
+		return
+	}
+	domain = strings.TrimPrefix(strings.ToLower(domain), ".")
+	for key, c := range s.entries {
+		if !c.inDomain(domain) {
+			continue
+		}
+		u, hc := c.httpCookie()
+		hc.MaxAge = -1
+		s.jar.SetCookies(u, []*http.Cookie{hc})
+		delete(s.entries, key)
+	}
+}
+
+//寫入匯出的Cookie 已過期的Cookie將被忽略
+func (s *CookieStorage) Import(cookies []*Cookie) {
+	now := time.Now()
+	for _, c := range cookies {
+		if c.Name == "" || c.Domain == "" || c.expired(now) {
+			continue
+		}
+		cc := *c
+		cc.Domain = strings.TrimPrefix(strings.ToLower(cc.Domain), ".")
+		if cc.Path == "" {
+			cc.Path = "/"
+		}
+		u, hc := cc.httpCookie()
+		s.SetCookies(u, []*http.Cookie{hc})
+	}
+}
+
+//將所有未過期的Cookie以JSON格式匯出
+func (s *CookieStorage) ExportJSON(w io.Writer) error {
+	cookies := s.All()
+	if cookies == nil {
+		cookies = []*Cookie{}
+	}
+	return json.NewEncoder(w).Encode(cookies)
+}
+
+//匯入由ExportJSON所匯出的Cookie
+func (s *CookieStorage) ImportJSON(r io.Reader) error {
+	var cookies []*Cookie
+	if err := json.NewDecoder(r).Decode(&cookies); err != nil {
+		return err
+	}
+	s.Import(cookies)
+	return nil
+}
+
+//將所有未過期的Cookie以Netscape cookies.txt格式匯出
+func (s *CookieStorage) ExportNetscape(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintln(bw, "# Netscape HTTP Cookie File")
+	for _, c := range s.All() {
+		domain := c.Domain
+		if !c.HostOnly {
+			domain = "." + domain
+		}
+		if c.HttpOnly {
+			domain = netscapeHttpOnlyPrefix + domain
+		}
+		var expires int64
+		if !c.Expires.IsZero() {
+			expires = c.Expires.Unix()
+		}
+		fmt.Fprintf(bw, "%s\t%s\t%s\t%s\t%d\t%s\t%s\n",
+			domain, netscapeBool(!c.HostOnly), c.Path, netscapeBool(c.Secure), expires, c.Name, c.Value,
+		)
+	}
+	return bw.Flush()
+}
+
+//匯入Netscape cookies.txt格式的Cookie 格式錯誤的行將被忽略
+func (s *CookieStorage) ImportNetscape(r io.Reader) error {
+	var cookies []*Cookie
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		c := &Cookie{}
+		if strings.HasPrefix(line, netscapeHttpOnlyPrefix) {
+			c.HttpOnly = true
+			line = strings.TrimPrefix(line, netscapeHttpOnlyPrefix)
+		}
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Split(line, "\t")
+		if len(fields) != 7 {
+			continue
+		}
+		expires, err := strconv.ParseInt(fields[4], 10, 64)
+		if err != nil {
+			continue
+		}
+		if expires > 0 {
+			c.Expires = time.Unix(expires, 0)
+		}
+		c.Domain = fields[0]
+		c.HostOnly = !strings.EqualFold(fields[1], "TRUE")
+		c.Path = fields[2]
+		c.Secure = strings.EqualFold(fields[3], "TRUE")
+		c.Name = fields[5]
+		c.Value = fields[6]
+		cookies = append(cookies, c)
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	s.Import(cookies)
+	return nil
+}
+
+func netscapeBool(b bool) string {
+	if b {
+		return "TRUE"
+	}
+	return "FALSE"
+}
+
+//依照RFC 6265的規則 將Respons中的http.Cookie轉為Cookie
+//當Cookie的Domain不屬於請求的域名或是為公共後綴時返回false
+func newCookie(u *url.URL, hc *http.Cookie, now time.Time) (*Cookie, bool) {
+	host := strings.ToLower(u.Hostname())
+	if hc.Name == "" || host == "" {
+		return nil, false
+	}
+	c := &Cookie{
+		Name:     hc.Name,
+		Value:    hc.Value,
+		Path:     hc.Path,
+		Secure:   hc.Secure,
+		HttpOnly: hc.HttpOnly,
+	}
+
+	domain := strings.TrimPrefix(strings.ToLower(hc.Domain), ".")
+	if domain == "" || domain == host {
+		c.Domain = host
+		c.HostOnly = domain == ""
+	} else {
+		if !strings.HasSuffix(host, "."+domain) {
+			return nil, false
+		}
+		if ps, _ := publicsuffix.PublicSuffix(domain); ps == domain {
+			return nil, false
+		}
+		c.Domain = domain
+	}
+
+	if c.Path == "" || c.Path[0] != '/' {
+		c.Path = defaultCookiePath(u.Path)
+	}
+
+	switch {
+	case hc.MaxAge < 0:
+		c.Expires = time.Unix(1, 0)
+	case hc.MaxAge > 0:
+		c.Expires = now.Add(time.Duration(hc.MaxAge) * time.Second)
+	case !hc.Expires.IsZero():
+		c.Expires = hc.Expires
+	}
+	return c, true
+}
+
+//RFC 6265 5.1.4 Cookie未設置Path時的默認值
+func defaultCookiePath(p string) string {
+	if p == "" || p[0] != '/' {
+		return "/"
+	}
+	i := strings.LastIndex(p, "/")
+	if i == 0 {
+		return "/"
+	}
+	return p[:i]
+}
@@ -0,0 +1,189 @@
+package scrapingo
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+)
+
+//未完成的下載檔案的副檔名
+const partialSuffix = ".part"
+
+//Request設置了Download時 ResponsBody會直接寫入檔案 不保存在記憶體中
+//下載中的檔案為Path+".part" 完成並通過校驗後才重新命名為Path
+//下載時不受MaxBodySize限制 也不使用DiskCache
+type Download struct {
+	//檔案儲存的路徑
+	Path string
+	//為true時 若存在未完成的檔案則使用Range從中斷處繼續下載
+	Resume bool
+	//檔案的校驗碼(16進位) 為空時不進行校驗
+	Checksum string
+	//計算校驗碼所使用的Hash 為nil時使用sha256
+	Hash func() hash.Hash
+	//下載進度的回調 written為已寫入的大小 total為檔案總大小 未知時為-1
+	Progress func(req *Request, written, total int64)
+}
+
+//未完成的檔案的路徑
+func (d *Download) partial() string {
+	return d.Path + partialSuffix
+}
+
+//Resume為true時 依照未完成的檔案大小設置Range 返回已下載的大小
+func (d *Download) setRange(req *http.Request) (*http.Request, int64) {
+	if !d.Resume {
+		return req, 0
+	}
+	info, err := os.Stat(d.partial())
+	if err != nil || info.Size() == 0 {
+		return req, 0
+	}
+	req = req.Clone(req.Context())
+	req.Header.Set("Range", fmt.Sprintf("bytes=%d-", info.Size()))
+	return req, info.Size()
+}
+
+//判斷該Response是否為下載內容
+//206為Range的結果 416則可能是檔案已下載完成
+func isDownloadStatus(code int) bool {
+	return code == http.StatusOK || code == http.StatusPartialContent || code == http.StatusRequestedRangeNotSatisfiable
+}
+
+//將ResponsBody寫入檔案 完成後確認檔案大小以及校驗碼
+//offset為請求時已下載的大小 伺服器不支持Range時將重新下載
+func (d *Download) save(r *Request, resp *http.Response, offset int64) error {
+	total := int64(-1)
+	flag := os.O_CREATE | os.O_WRONLY
+
+	switch resp.StatusCode {
+	case http.StatusPartialContent:
+		start, size, ok := parseContentRange(resp.Header.Get("Content-Range"))
+		if !ok || start != offset {
+			return ErrDownloadIncomplete
+		}
+		total = size
+		flag |= os.O_APPEND
+	case http.StatusRequestedRangeNotSatisfiable:
+		//Content-Range: bytes */size 與已下載的大小相同時表示已下載完成
+		_, size, ok := parseContentRange(resp.Header.Get("Content-Range"))
+		if !ok || offset == 0 || size != offset {
+			return &HTTPStatusError{StatusCode: resp.StatusCode, Header: resp.Header, URL: resp.Request.URL}
+		}
+		return d.finish(offset, offset)
+	default:
+		offset = 0
+		if resp.ContentLength >= 0 && resp.Header.Get("Content-Encoding") == "" {
+			total = resp.ContentLength
+		}
+		flag |= os.O_TRUNC
+	}
+
+	f, err := os.OpenFile(d.partial(), flag, 0644)
+	if err != nil {
+		return err
+	}
+	body, err := decompress(resp)
+	if err != nil {
+		f.Close()
+		return err
+	}
+	w := &progressWriter{w: f, req: r, written: offset, total: total, progress: d.Progress}
+	_, err = io.Copy(w, body)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return err
+	}
+	return d.finish(w.written, total)
+}
+
+//確認檔案大小以及校驗碼 通過後將未完成的檔案重新命名為Path
+//校驗碼不符時刪除檔案
+func (d *Download) finish(written, total int64) error {
+	if total >= 0 && written != total {
+		return ErrDownloadIncomplete
+	}
+	if d.Checksum != "" {
+		sum, err := d.sum()
+		if err != nil {
+			return err
+		}
+		if !strings.EqualFold(sum, d.Checksum) {
+			os.Remove(d.partial())
+			return ErrChecksumMismatch
+		}
+	}
+	return os.Rename(d.partial(), d.Path)
+}
+
+//計算未完成的檔案的校驗碼
+func (d *Download) sum() (string, error) {
+	newHash := d.Hash
+	if newHash == nil {
+		newHash = sha256.New
+	}
+	f, err := os.Open(d.partial())
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	h := newHash()
+	if _, err = io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+//解析Content-Range 返回起始位置以及檔案總大小
+//格式為 bytes start-end/size 或 bytes */size
+func parseContentRange(v string) (start, size int64, ok bool) {
+	if !strings.HasPrefix(v, "bytes ") {
+		return 0, 0, false
+	}
+	v = strings.TrimPrefix(v, "bytes ")
+	i := strings.Index(v, "/")
+	if i < 0 {
+		return 0, 0, false
+	}
+	size, err := strconv.ParseInt(v[i+1:], 10, 64)
+	if err != nil {
+		//總大小未知
+		size = -1
+	}
+	if r := v[:i]; r != "*" {
+		j := strings.Index(r, "-")
+		if j < 0 {
+			return 0, 0, false
+		}
+		if start, err = strconv.ParseInt(r[:j], 10, 64); err != nil {
+			return 0, 0, false
+		}
+	}
+	return start, size, true
+}
+
+//寫入時調用Download.Progress回報進度
+type progressWriter struct {
+	w        io.Writer
+	req      *Request
+	written  int64
+	total    int64
+	progress func(*Request, int64, int64)
+}
+
+func (p *progressWriter) Write(b []byte) (int, error) {
+	n, err := p.w.Write(b)
+	p.written += int64(n)
+	if p.progress != nil {
+		p.progress(p.req, p.written, p.total)
+	}
+	return n, err
+}
@@ -180,6 +180,42 @@ func (e *ConcurrentEngine) AddLimits(l []*Limiter) error {
 	return e.C.AddLimits(l)
 }
 
+//添加URL符合domainGlob時所使用的Authenticator
+func (e *ConcurrentEngine) AddAuthenticator(domainGlob string, a Authenticator) error {
+	return e.C.AddAuthenticator(domainGlob, a)
+}
+
+//添加需要登入的網站 (參考scrapingo.Session)
+func (e *ConcurrentEngine) AddSession(s *Session) error {
+	return e.C.AddSession(s)
+}
+
+//添加LinkExtractor 所取出的連結會自動提交至Scheduler
+func (e *ConcurrentEngine) AddLinkExtractor(l *LinkExtractor) {
+	e.C.AddLinkExtractor(l)
+}
+
+//讀取sitemap 並將其中的URL提交至Scheduler (參考scrapingo.Sitemap)
+func (e *ConcurrentEngine) SubmitSitemap(URL string, s *Sitemap) error {
+	req, err := s.Request(URL)
+	if err != nil {
+		return err
+	}
+	e.Submit(req)
+	return nil
+}
+
+//讀取RSS或Atom 並將新項目的連結提交至Scheduler (參考scrapingo.FeedReader)
+//重複提交同一個URL時只會處理新的項目
+func (e *ConcurrentEngine) SubmitFeed(URL string, f *FeedReader) error {
+	req, err := f.Request(URL)
+	if err != nil {
+		return err
+	}
+	e.Submit(req)
+	return nil
+}
+
 //提交新的Request至Scheduler
 func (e *ConcurrentEngine) Submit(req *Request) {
 	e.engineScheduler.Submit(req)
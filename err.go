@@ -1,6 +1,11 @@
 package scrapingo
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+)
 
 var (
 	//scrapingo.Engine的closed參數為true 仍調用Run or RunWithContext 時的錯誤
@@ -12,7 +17,92 @@ var (
 	//當Request的儲存總數超過所設定的值時進行Panic
 	ErrOverMaxRequestStorage = errors.New("scrapingo: RequestStorage MaxSize Reached")
 	//當limiter的參數urlGlob為match.Nothing時的錯誤
-	ErrlimiterNoParttern = errors.New("scrapingo: limiter cannt No Parttern")
+	//errors.Is(ErrlimiterNoParttern, ErrNoDomainPattern)為true
+	ErrlimiterNoParttern error = &wrapError{msg: "scrapingo: limiter cannt No Parttern", err: ErrNoDomainPattern}
+	//Limiter Auth以及Session的DomainGlob為match.Nothing時的錯誤
+	ErrNoDomainPattern = errors.New("scrapingo: DomainGlob has no pattern")
 	//當重複訪問相同URL時發生此錯誤
 	ErrIsVisitedURL = errors.New("scrapingo: URL is Visited")
+	//進行重試時Request的Body無法重新讀取時的錯誤
+	ErrBodyNotReplayable = errors.New("scrapingo: Request Body is not replayable")
+	//Collector未設置CookieStorage 仍調用SetCookies時的錯誤
+	ErrNoCookieJar = errors.New("scrapingo: Collector has no CookieJar")
+	//初始化ProxyPool時沒有傳入任何Proxy的錯誤
+	ErrNoProxy = errors.New("scrapingo: ProxyPool has no Proxy")
+	//ProxyPool中所有Proxy皆暫停使用時的錯誤
+	ErrNoAvailableProxy = errors.New("scrapingo: no available Proxy")
+	//設置ProxySwitcher時Client.Transport不為*http.Transport的錯誤
+	ErrUnsupportedTransport = errors.New("scrapingo: Client.Transport is not *http.Transport")
+	//URL被robots.txt禁止訪問 或是無法讀取robots.txt時的錯誤
+	ErrRobotsTxtDisallowed = errors.New("scrapingo: URL is disallowed by robots.txt")
+	//所指定的Encoding不存在時的錯誤
+	ErrUnknownEncoding = errors.New("scrapingo: unknown Encoding")
+	//下載的檔案大小與Respons所提供的大小不符時的錯誤
+	ErrDownloadIncomplete = errors.New("scrapingo: Download is incomplete")
+	//下載的檔案校驗碼不符時的錯誤
+	ErrChecksumMismatch = errors.New("scrapingo: Download Checksum mismatch")
+	//URL不符合Collector的域名或URLFilter限制時的錯誤
+	ErrFilteredURL = errors.New("scrapingo: URL is filtered")
+	//ResponsBody不是合法的JSON時的錯誤
+	ErrInvalidJSON = errors.New("scrapingo: invalid JSON")
+	//ResponsBody不是RSS或Atom時的錯誤
+	ErrUnknownFeed = errors.New("scrapingo: unknown feed format")
+	//頁面中找不到符合的<form>時的錯誤
+	ErrFormNotFound = errors.New("scrapingo: form not found")
+	//重新登入後Response仍被判斷為Session過期時的錯誤
+	ErrSessionExpired = errors.New("scrapingo: session expired")
 )
+
+//當Respons的StatusCode不被Collector接受時的錯誤
+//可透過errors.As取得StatusCode Header 以及ResponsBody
+type HTTPStatusError struct {
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+	URL        *url.URL
+}
+
+func (e *HTTPStatusError) Error() string {
+	return fmt.Sprintf("scrapingo: Respons StatusCode is %d", e.StatusCode)
+}
+
+//解析函式發生panic時的錯誤
+//Value為recover()的返回值 Stack為發生panic時的堆疊
+type ParsePanicError struct {
+	Value interface{}
+	Stack []byte
+	URL   *url.URL
+}
+
+func (e *ParsePanicError) Error() string {
+	return fmt.Sprintf("scrapingo: ParseFunc panic: %v", e.Value)
+}
+
+//Authenticator取得認證資訊失敗時的錯誤
+//Code為token endpoint所返回的error 例: invalid_client
+type AuthError struct {
+	StatusCode int
+	Code       string
+	Body       []byte
+}
+
+func (e *AuthError) Error() string {
+	if e.Code != "" {
+		return fmt.Sprintf("scrapingo: authentication failed: %s (StatusCode %d)", e.Code, e.StatusCode)
+	}
+	return fmt.Sprintf("scrapingo: authentication failed: StatusCode %d", e.StatusCode)
+}
+
+//保留自身的訊息 並可透過errors.Is匹配err
+type wrapError struct {
+	msg string
+	err error
+}
+
+func (e *wrapError) Error() string {
+	return e.msg
+}
+
+func (e *wrapError) Unwrap() error {
+	return e.err
+}
@@ -134,27 +134,17 @@ var (
 	posttime = regexp.MustCompile(`class="edittime [\s\S]+? data-mtime="([^"]+?)"`)
 )
 
-func ArticleList(body []byte) (result *scrapingo.ParseResult) {
-	defer func() {
-		if r := recover(); r != nil {
-			log.Println(r)
-			result = new(scrapingo.ParseResult)
-		}
-	}()
+//解析時發生的panic會由Collector轉為*scrapingo.ParsePanicError
+func ArticleList(body []byte) *scrapingo.ParseResult {
 	list := b_list.Find(body)
 	matches := title.FindAllSubmatch(list, -1)
 
-	result = new(scrapingo.ParseResult)
+	result := new(scrapingo.ParseResult)
 	for _, match := range matches {
-		BaHa := new(Baha)
-		BaHa.Title = string(match[2])
 		req, err := scrapingo.NewRequest(
 			string(match[1]),
-			scrapingo.ParseFunction(
-				func(b []byte) *scrapingo.ParseResult {
-					return Article(b, BaHa)
-				},
-			),
+			scrapingo.MetaValue("title", string(match[2])),
+			scrapingo.ResponseParseFunction(Article),
 		)
 		if err != nil {
 			continue
@@ -163,14 +153,12 @@ func ArticleList(body []byte) (result *scrapingo.ParseResult) {
 	}
 	return result
 }
-func Article(body []byte, b *Baha) (result *scrapingo.ParseResult) {
-	defer func() {
-		if r := recover(); r != nil {
-			log.Println(r)
-			result = new(scrapingo.ParseResult)
-		}
-	}()
-	result = new(scrapingo.ParseResult)
+func Article(resp *scrapingo.Response) *scrapingo.ParseResult {
+	result := new(scrapingo.ParseResult)
+
+	body := resp.Body
+	b := new(Baha)
+	b.Title = resp.Request.Meta.GetString("title")
 
 	account := account.FindSubmatch(body)
 	author := author.FindSubmatch(body)
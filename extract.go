@@ -0,0 +1,285 @@
+package scrapingo
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/andybalholm/cascadia"
+	"github.com/antchfx/htmlquery"
+	"golang.org/x/net/html"
+)
+
+var (
+	timeType  = reflect.TypeOf(time.Time{})
+	modelType = reflect.TypeOf(Model{})
+	//已編譯的正則表達式
+	regexCache sync.Map
+)
+
+//將HTML格式的ResponsBody解析至v v必須為struct的指標
+//
+//依照struct tag從HTML中取出值 支持的tag:
+//  selector:"h1.title"     CSS選擇器
+//  xpath:"//h1"            XPath
+//  regex:"id=(\d+)"        正則表達式 有子匹配時取第一個子匹配
+//  attr:"href"             取屬性值 未設置時取文字
+//  layout:"2006-01-02"     time.Time的格式 默認為RFC3339
+//
+//struct欄位在設置selector或xpath時 以第一個符合的元素為範圍繼續解析
+//slice欄位會取所有符合的元素 例：
+//  type Article struct {
+//      scrapingo.Model
+//      Title string    `selector:"h1"`
+//      Tags  []string  `selector:".tag"`
+//      Time  time.Time `selector:"time" attr:"datetime" layout:"2006-01-02"`
+//      Links []struct {
+//          Text string `selector:"a"`
+//          URL  string `selector:"a" attr:"href"`
+//      } `selector:"ul.links li"`
+//  }
+func (r *Response) Unmarshal(v interface{}) error {
+	doc, err := html.Parse(bytes.NewReader(r.Body))
+	if err != nil {
+		return err
+	}
+	return unmarshalNode(doc, v)
+}
+
+//以該元素為範圍解析至v v必須為struct的指標
+func (e *HTMLElement) Unmarshal(v interface{}) error {
+	return unmarshalNode(e.DOM, v)
+}
+
+//返回將ResponsBody依照struct tag解析為item的ParseFuncWithError
+//每次解析時會依照prototype的型別建立新的item 並加入至ParseResult.Items
+//prototype需為struct的指標 嵌入scrapingo.Model時會自動賦予對應的值
+//prototype不是struct的指標時 所返回的ParseFuncWithError皆返回錯誤
+func ExtractParse(prototype interface{}) ParseFuncWithError {
+	typ := reflect.TypeOf(prototype)
+	if typ == nil || typ.Kind() != reflect.Ptr || typ.Elem().Kind() != reflect.Struct {
+		err := fmt.Errorf("scrapingo: ExtractParse requires a struct pointer prototype, got %T", prototype)
+		return func(*Response) (*ParseResult, error) {
+			return nil, err
+		}
+	}
+	typ = typ.Elem()
+
+	return func(resp *Response) (*ParseResult, error) {
+		item := reflect.New(typ).Interface()
+		if err := resp.Unmarshal(item); err != nil {
+			return nil, err
+		}
+		return &ParseResult{Items: []interface{}{item}}, nil
+	}
+}
+
+func unmarshalNode(n *html.Node, v interface{}) error {
+	val := reflect.ValueOf(v)
+	if val.Kind() != reflect.Ptr || val.IsNil() || val.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("scrapingo: Unmarshal requires a non-nil struct pointer, got %T", v)
+	}
+	return unmarshalStruct(n, val.Elem())
+}
+
+//依序解析struct的每個欄位 沒有tag的欄位將被忽略
+func unmarshalStruct(n *html.Node, val reflect.Value) error {
+	typ := val.Type()
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		if field.PkgPath != "" || field.Type == modelType {
+			continue
+		}
+		tag := field.Tag
+		if tag.Get("selector") == "" && tag.Get("xpath") == "" && tag.Get("regex") == "" {
+			continue
+		}
+		if err := unmarshalField(n, val.Field(i), tag); err != nil {
+			return fmt.Errorf("scrapingo: field %s: %w", field.Name, err)
+		}
+	}
+	return nil
+}
+
+func unmarshalField(n *html.Node, val reflect.Value, tag reflect.StructTag) error {
+	if val.Kind() == reflect.Ptr {
+		if values, _, err := selectAll(n, tag); err != nil || len(values) == 0 {
+			return err
+		}
+		elem := reflect.New(val.Type().Elem())
+		if err := unmarshalField(n, elem.Elem(), tag); err != nil {
+			return err
+		}
+		val.Set(elem)
+		return nil
+	}
+
+	if val.Kind() == reflect.Slice {
+		values, nodes, err := selectAll(n, tag)
+		if err != nil {
+			return err
+		}
+		elemType := val.Type().Elem()
+		slice := reflect.MakeSlice(val.Type(), 0, len(values))
+		for j := range values {
+			elem := reflect.New(elemType).Elem()
+			switch {
+			case isNestedStruct(elemType):
+				if nodes == nil {
+					continue
+				}
+				err = unmarshalStruct(nodes[j], elem)
+			case elemType.Kind() == reflect.Ptr && isNestedStruct(elemType.Elem()):
+				if nodes == nil {
+					continue
+				}
+				elem = reflect.New(elemType.Elem())
+				err = unmarshalStruct(nodes[j], elem.Elem())
+			default:
+				err = setValue(elem, values[j], tag)
+			}
+			if err != nil {
+				return err
+			}
+			slice = reflect.Append(slice, elem)
+		}
+		val.Set(slice)
+		return nil
+	}
+
+	values, nodes, err := selectAll(n, tag)
+	if err != nil || len(values) == 0 {
+		return err
+	}
+	if isNestedStruct(val.Type()) {
+		if nodes == nil {
+			return nil
+		}
+		return unmarshalStruct(nodes[0], val)
+	}
+	return setValue(val, values[0], tag)
+}
+
+//是否為需繼續解析的struct
+func isNestedStruct(t reflect.Type) bool {
+	return t.Kind() == reflect.Struct && t != timeType
+}
+
+//返回所有符合tag的值 以及所對應的節點
+//使用regex時沒有對應的節點
+func selectAll(n *html.Node, tag reflect.StructTag) ([]string, []*html.Node, error) {
+	var nodes []*html.Node
+	switch {
+	case tag.Get("selector") != "":
+		sel, err := cascadia.Compile(tag.Get("selector"))
+		if err != nil {
+			return nil, nil, err
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			nodes = append(nodes, sel.MatchAll(c)...)
+		}
+	case tag.Get("xpath") != "":
+		var err error
+		if nodes, err = htmlquery.QueryAll(n, tag.Get("xpath")); err != nil {
+			return nil, nil, err
+		}
+	default:
+		re, err := compileRegex(tag.Get("regex"))
+		if err != nil {
+			return nil, nil, err
+		}
+		buf := &bytes.Buffer{}
+		html.Render(buf, n)
+		var values []string
+		for _, match := range re.FindAllStringSubmatch(buf.String(), -1) {
+			if len(match) > 1 {
+				values = append(values, match[1])
+			} else {
+				values = append(values, match[0])
+			}
+		}
+		return values, nil, nil
+	}
+
+	attr := tag.Get("attr")
+	values := make([]string, len(nodes))
+	for i, node := range nodes {
+		if attr != "" {
+			values[i] = htmlquery.SelectAttr(node, attr)
+		} else {
+			values[i] = strings.TrimSpace(nodeText(node))
+		}
+	}
+	return values, nodes, nil
+}
+
+func compileRegex(expr string) (*regexp.Regexp, error) {
+	if re, ok := regexCache.Load(expr); ok {
+		return re.(*regexp.Regexp), nil
+	}
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return nil, err
+	}
+	regexCache.Store(expr, re)
+	return re, nil
+}
+
+//將字串轉為欄位的型別後賦值
+func setValue(val reflect.Value, s string, tag reflect.StructTag) error {
+	if val.Type() == timeType {
+		if s == "" {
+			return nil
+		}
+		layout := tag.Get("layout")
+		if layout == "" {
+			layout = time.RFC3339
+		}
+		t, err := time.Parse(layout, s)
+		if err != nil {
+			return err
+		}
+		val.Set(reflect.ValueOf(t))
+		return nil
+	}
+
+	if s == "" && val.Kind() != reflect.String {
+		return nil
+	}
+	switch val.Kind() {
+	case reflect.String:
+		val.SetString(s)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(strings.ReplaceAll(s, ",", ""), 10, val.Type().Bits())
+		if err != nil {
+			return err
+		}
+		val.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		u, err := strconv.ParseUint(strings.ReplaceAll(s, ",", ""), 10, val.Type().Bits())
+		if err != nil {
+			return err
+		}
+		val.SetUint(u)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(strings.ReplaceAll(s, ",", ""), val.Type().Bits())
+		if err != nil {
+			return err
+		}
+		val.SetFloat(f)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return err
+		}
+		val.SetBool(b)
+	default:
+		return fmt.Errorf("unsupported type %s", val.Type())
+	}
+	return nil
+}
@@ -0,0 +1,336 @@
+package scrapingo
+
+import (
+	"bytes"
+	"encoding/xml"
+	"hash/fnv"
+	"io"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+//RSS 2.0 RSS 1.0 以及Atom的項目 統一為相同的格式
+//作為item返回時會賦予scrapingo.Model的值
+type FeedEntry struct {
+	Model
+	//RSS的guid或Atom的id 未設置時為Link
+	GUID      string
+	Title     string
+	Link      string
+	Summary   string
+	Content   string
+	Author    string
+	Published time.Time
+	Updated   time.Time
+	//所屬的Feed的URL
+	FeedURL    string
+	Categories []string
+}
+
+//解析後的RSS或Atom
+type Feed struct {
+	Title       string
+	Link        string
+	Description string
+	Updated     time.Time
+	Entries     []*FeedEntry
+}
+
+//<link> RSS使用文字 Atom使用href屬性
+type feedLink struct {
+	XMLName xml.Name
+	Href    string `xml:"href,attr"`
+	Rel     string `xml:"rel,attr"`
+	Text    string `xml:",chardata"`
+}
+
+//返回第一個可用的連結 Atom優先使用rel="alternate"
+func pickLink(links []feedLink) string {
+	for _, l := range links {
+		if l.Href != "" && (l.Rel == "" || l.Rel == "alternate") {
+			return strings.TrimSpace(l.Href)
+		}
+		if text := strings.TrimSpace(l.Text); text != "" {
+			return text
+		}
+	}
+	return ""
+}
+
+//RSS 2.0以及RSS 1.0的<item>
+type rssItem struct {
+	Title       string     `xml:"title"`
+	Links       []feedLink `xml:"link"`
+	Description string     `xml:"description"`
+	Encoded     string     `xml:"encoded"`
+	GUID        string     `xml:"guid"`
+	About       string     `xml:"about,attr"`
+	PubDate     string     `xml:"pubDate"`
+	Date        string     `xml:"date"`
+	Author      string     `xml:"author"`
+	Creator     string     `xml:"creator"`
+	Categories  []string   `xml:"category"`
+}
+
+//RSS 2.0的<item>位於<channel>中 RSS 1.0則與<channel>同層
+type rssFeed struct {
+	Channel struct {
+		Title         string     `xml:"title"`
+		Links         []feedLink `xml:"link"`
+		Description   string     `xml:"description"`
+		LastBuildDate string     `xml:"lastBuildDate"`
+		Date          string     `xml:"date"`
+		Items         []rssItem  `xml:"item"`
+	} `xml:"channel"`
+	Items []rssItem `xml:"item"`
+}
+
+type atomFeed struct {
+	Title    string     `xml:"title"`
+	Subtitle string     `xml:"subtitle"`
+	Links    []feedLink `xml:"link"`
+	Updated  string     `xml:"updated"`
+	Entries  []struct {
+		ID        string     `xml:"id"`
+		Title     string     `xml:"title"`
+		Links     []feedLink `xml:"link"`
+		Summary   string     `xml:"summary"`
+		Content   string     `xml:"content"`
+		Published string     `xml:"published"`
+		Updated   string     `xml:"updated"`
+		Authors   []struct {
+			Name string `xml:"name"`
+		} `xml:"author"`
+		Categories []struct {
+			Term string `xml:"term,attr"`
+		} `xml:"category"`
+	} `xml:"entry"`
+}
+
+//RSS所使用的日期格式
+var feedDateLayouts = []string{
+	time.RFC1123Z,
+	time.RFC1123,
+	"Mon, 2 Jan 2006 15:04:05 -0700",
+	"Mon, 2 Jan 2006 15:04:05 MST",
+	"2 Jan 2006 15:04:05 -0700",
+	time.RFC822Z,
+	time.RFC822,
+}
+
+//解析RSS或Atom的日期 無法解析時返回零值
+func parseFeedDate(s string) time.Time {
+	s = strings.TrimSpace(s)
+	for _, layout := range feedDateLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t
+		}
+	}
+	return parseW3CDate(s)
+}
+
+//解析RSS 2.0 RSS 1.0或Atom 無法辨識時返回ErrUnknownFeed
+//body需為UTF-8 XML宣告中的encoding會被忽略
+func ParseFeed(body []byte) (*Feed, error) {
+	body = xmlEncodingDecl.ReplaceAll(body, []byte(`${1}encoding="UTF-8"`))
+
+	root, err := feedRoot(body)
+	if err != nil {
+		return nil, err
+	}
+	switch root {
+	case "rss", "RDF":
+		return parseRSS(body)
+	case "feed":
+		return parseAtom(body)
+	}
+	return nil, ErrUnknownFeed
+}
+
+//返回XML根元素的名稱
+func feedRoot(body []byte) (string, error) {
+	d := xml.NewDecoder(bytes.NewReader(body))
+	for {
+		tok, err := d.Token()
+		if err == io.EOF {
+			return "", ErrUnknownFeed
+		}
+		if err != nil {
+			return "", err
+		}
+		if start, ok := tok.(xml.StartElement); ok {
+			return start.Name.Local, nil
+		}
+	}
+}
+
+func parseRSS(body []byte) (*Feed, error) {
+	var raw rssFeed
+	if err := xml.Unmarshal(body, &raw); err != nil {
+		return nil, err
+	}
+	ch := raw.Channel
+	feed := &Feed{
+		Title:       strings.TrimSpace(ch.Title),
+		Link:        pickLink(ch.Links),
+		Description: strings.TrimSpace(ch.Description),
+		Updated:     parseFeedDate(ch.LastBuildDate),
+	}
+	if feed.Updated.IsZero() {
+		feed.Updated = parseFeedDate(ch.Date)
+	}
+	for _, item := range append(ch.Items, raw.Items...) {
+		entry := &FeedEntry{
+			GUID:       strings.TrimSpace(item.GUID),
+			Title:      strings.TrimSpace(item.Title),
+			Link:       pickLink(item.Links),
+			Summary:    strings.TrimSpace(item.Description),
+			Content:    strings.TrimSpace(item.Encoded),
+			Author:     strings.TrimSpace(item.Author),
+			Published:  parseFeedDate(item.PubDate),
+			Categories: item.Categories,
+		}
+		if entry.Published.IsZero() {
+			entry.Published = parseFeedDate(item.Date)
+		}
+		if entry.Author == "" {
+			entry.Author = strings.TrimSpace(item.Creator)
+		}
+		if entry.Link == "" {
+			entry.Link = strings.TrimSpace(item.About)
+		}
+		if entry.GUID == "" {
+			entry.GUID = entry.Link
+		}
+		entry.Updated = entry.Published
+		feed.Entries = append(feed.Entries, entry)
+	}
+	return feed, nil
+}
+
+func parseAtom(body []byte) (*Feed, error) {
+	var raw atomFeed
+	if err := xml.Unmarshal(body, &raw); err != nil {
+		return nil, err
+	}
+	feed := &Feed{
+		Title:       strings.TrimSpace(raw.Title),
+		Link:        pickLink(raw.Links),
+		Description: strings.TrimSpace(raw.Subtitle),
+		Updated:     parseFeedDate(raw.Updated),
+	}
+	for _, e := range raw.Entries {
+		entry := &FeedEntry{
+			GUID:      strings.TrimSpace(e.ID),
+			Title:     strings.TrimSpace(e.Title),
+			Link:      pickLink(e.Links),
+			Summary:   strings.TrimSpace(e.Summary),
+			Content:   strings.TrimSpace(e.Content),
+			Published: parseFeedDate(e.Published),
+			Updated:   parseFeedDate(e.Updated),
+		}
+		if len(e.Authors) > 0 {
+			entry.Author = strings.TrimSpace(e.Authors[0].Name)
+		}
+		for _, c := range e.Categories {
+			entry.Categories = append(entry.Categories, c.Term)
+		}
+		if entry.Published.IsZero() {
+			entry.Published = entry.Updated
+		}
+		if entry.GUID == "" {
+			entry.GUID = entry.Link
+		}
+		feed.Entries = append(feed.Entries, entry)
+	}
+	return feed, nil
+}
+
+//讀取RSS或Atom 新的項目會作為*FeedEntry加入至ParseResult.Items
+//已讀取過的項目會依照GUID記錄至Seen 重複讀取同一個Feed時只返回新的項目
+type FeedReader struct {
+	//項目連結所使用的ParseFunc 為nil時不對項目連結進行請求
+	Parse ParseFunc
+	//項目連結的其他RequestOption
+	Options []RequestOption
+	//記錄已讀取過的項目 為nil時在第一次讀取時使用默認的去重儲存
+	//可以參考(scrapingo.visitStorage)interface自行定義
+	Seen VisitStorage
+	//確認以及記錄Seen時持有 避免同時讀取同一個Feed時重複返回項目
+	mu sync.Mutex
+}
+
+//傳入項目連結所使用的ParseFunc初始化FeedReader
+func NewFeedReader(p ParseFunc) *FeedReader {
+	return &FeedReader{Parse: p, Seen: defaultHasStorage()}
+}
+
+//返回讀取Feed的Request 該Request不進行去重 可重複請求
+func (f *FeedReader) Request(URL string) (*Request, error) {
+	return NewRequest(URL, ParseFunctionWithError(f.parse), DontFilter(true), NoCache(true))
+}
+
+//解析Feed 只返回未讀取過的項目
+func (f *FeedReader) parse(resp *Response) (*ParseResult, error) {
+	feed, err := ParseFeed(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	result := &ParseResult{}
+	for _, entry := range feed.Entries {
+		entry.Link = absoluteURL(resp.URL, entry.Link)
+		entry.FeedURL = resp.URL.String()
+
+		if !f.markSeen(f.guid(resp.URL, entry)) {
+			continue
+		}
+		result.Items = append(result.Items, entry)
+
+		if f.Parse == nil || entry.Link == "" {
+			continue
+		}
+		req, err := NewRequest(entry.Link, append([]RequestOption{ParseFunction(f.Parse)}, f.Options...)...)
+		if err != nil {
+			continue
+		}
+		result.Requests = append(result.Requests, req)
+	}
+	return result, nil
+}
+
+//GUID以Feed的Host為範圍計算哈希值
+//沒有GUID以及Link時 以標題 發布時間以及摘要計算
+func (f *FeedReader) guid(u *url.URL, entry *FeedEntry) uint64 {
+	id := entry.GUID
+	if id == "" {
+		id = entry.Title + "\n" + entry.Published.Format(time.RFC3339) + "\n" + entry.Summary
+	}
+	h := fnv.New64a()
+	h.Write([]byte(u.Host + "\n" + id))
+	return h.Sum64()
+}
+
+//記錄該項目 已讀取過時返回false
+func (f *FeedReader) markSeen(key uint64) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.Seen == nil {
+		f.Seen = defaultHasStorage()
+	}
+	if f.Seen.IsVisited(key) {
+		return false
+	}
+	f.Seen.Visited(key)
+	return true
+}
+
+//讀取RSS或Atom 只返回未讀取過的項目 (參考scrapingo.FeedReader)
+func (c *Collector) Feed(URL string, f *FeedReader) (*ParseResult, error) {
+	req, err := f.Request(URL)
+	if err != nil {
+		return nil, err
+	}
+	return c.Request(req)
+}
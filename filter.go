@@ -0,0 +1,96 @@
+package scrapingo
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/gobwas/glob"
+)
+
+//判斷URL是否符合條件 可使用RegexpFilter或GlobFilter初始化
+//glob.Glob也實現了此interface
+type URLFilter interface {
+
+	//傳入完整的URL字串 符合時返回true
+
+	Match(string) bool
+}
+
+//以正則表達式匹配URL的URLFilter
+type regexpFilter struct {
+	re *regexp.Regexp
+}
+
+func (f *regexpFilter) Match(u string) bool {
+	return f.re.MatchString(u)
+}
+
+func (f *regexpFilter) String() string {
+	return f.re.String()
+}
+
+//傳入正則表達式初始化URLFilter
+func RegexpFilter(expr string) (URLFilter, error) {
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return nil, err
+	}
+	return &regexpFilter{re: re}, nil
+}
+
+//傳入glob初始化URLFilter 語法與Limiter.DomainGlob相同
+func GlobFilter(pattern string) (URLFilter, error) {
+	return glob.Compile(pattern)
+}
+
+//判斷host是否屬於所列出的域名 子域名也視為符合
+func matchDomain(domains []string, host string) bool {
+	for _, domain := range domains {
+		domain = asciiDomain(strings.TrimPrefix(domain, "."))
+		if host == domain || strings.HasSuffix(host, "."+domain) {
+			return true
+		}
+	}
+	return false
+}
+
+//將域名轉為小寫的ASCII 國際化域名轉為punycode 無法轉換時返回小寫的原字串
+func asciiDomain(domain string) string {
+	domain = strings.ToLower(domain)
+	if ascii, err := idnaProfile.ToASCII(domain); err == nil {
+		return strings.ToLower(ascii)
+	}
+	return domain
+}
+
+//判斷URL是否符合任一個URLFilter
+func matchFilters(filters []URLFilter, u string) bool {
+	for _, f := range filters {
+		if f.Match(u) {
+			return true
+		}
+	}
+	return false
+}
+
+//依照Collector的AllowedDomains DisallowedDomains URLFilters DisallowedURLFilters
+//判斷是否允許訪問該URL 不允許時返回ErrFilteredURL
+func (c *Collector) checkFilters(u *url.URL) error {
+	host := asciiDomain(u.Hostname())
+	if len(c.DisallowedDomains) > 0 && matchDomain(c.DisallowedDomains, host) {
+		return ErrFilteredURL
+	}
+	if len(c.AllowedDomains) > 0 && !matchDomain(c.AllowedDomains, host) {
+		return ErrFilteredURL
+	}
+
+	s := u.String()
+	if len(c.DisallowedURLFilters) > 0 && matchFilters(c.DisallowedURLFilters, s) {
+		return ErrFilteredURL
+	}
+	if len(c.URLFilters) > 0 && !matchFilters(c.URLFilters, s) {
+		return ErrFilteredURL
+	}
+	return nil
+}
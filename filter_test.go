@@ -0,0 +1,39 @@
+package scrapingo
+
+import (
+	"net/url"
+	"testing"
+)
+
+func TestAllowedDomainsIDN(t *testing.T) {
+	tests := []struct {
+		domains []string
+		URL     string
+		allowed bool
+	}{
+		{[]string{"例え.jp"}, "http://xn--r8jz45g.jp/", true},
+		{[]string{"例え.jp"}, "http://www.xn--r8jz45g.jp/page", true},
+		{[]string{"例え.jp"}, "http://例え.jp/", true},
+		{[]string{"xn--r8jz45g.jp"}, "http://例え.jp/", true},
+		{[]string{"EXAMPLE.com"}, "http://www.Example.COM/", true},
+		{[]string{"例え.jp"}, "http://example.jp/", false},
+	}
+	for _, test := range tests {
+		c := NewCollector(AllowedDomains(test.domains...))
+		u, err := url.Parse(test.URL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if allowed := c.checkFilters(u) == nil; allowed != test.allowed {
+			t.Errorf("AllowedDomains(%v) %s allowed = %v, want %v", test.domains, test.URL, allowed, test.allowed)
+		}
+	}
+}
+
+func TestDisallowedDomainsIDN(t *testing.T) {
+	c := NewCollector(DisallowedDomains("例え.jp"))
+	u, _ := url.Parse("http://sub.xn--r8jz45g.jp/")
+	if err := c.checkFilters(u); err != ErrFilteredURL {
+		t.Fatalf("err = %v, want ErrFilteredURL", err)
+	}
+}
@@ -0,0 +1,53 @@
+package scrapingo
+
+import (
+	"hash/fnv"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+//計算Request的指紋 指紋相同的Request視為重複請求
+//結果會交由VisitStorage進行去重 並作為DiskCache的Key
+//可以參考DefaultFingerprinter的實現方式
+type Fingerprinter interface {
+
+	//返回該Request的指紋 調用時URL已經過URLNormalizer格式化
+
+	Fingerprint(*Request) (uint64, error)
+}
+
+//實現Fingerprinter interface
+//以Method URL Body 以及Headers所列出的Header計算FNV指紋
+type DefaultFingerprinter struct {
+	//需納入指紋的Header名稱 例如Accept-Language
+	Headers []string
+}
+
+//傳入需納入指紋的Header名稱初始化DefaultFingerprinter
+func NewFingerprinter(headers ...string) *DefaultFingerprinter {
+	return &DefaultFingerprinter{Headers: headers}
+}
+
+//實現Fingerprinter interface的 Fingerprint(*Request) (uint64, error)
+func (f *DefaultFingerprinter) Fingerprint(req *Request) (uint64, error) {
+	h := fnv.New64a()
+	h.Write([]byte(strings.ToUpper(req.Method)))
+	h.Write([]byte{'\n'})
+	h.Write([]byte(req.URL.String()))
+	h.Write([]byte{'\n'})
+
+	headers := make([]string, 0, len(f.Headers))
+	for _, key := range f.Headers {
+		headers = append(headers, http.CanonicalHeaderKey(key))
+	}
+	sort.Strings(headers)
+	for _, key := range headers {
+		h.Write([]byte(key + ":" + strings.Join(req.Header.Values(key), ",") + "\n"))
+	}
+
+	if req.Body != nil {
+		h.Write(readertobyte(req.Body))
+	}
+	return h.Sum64(), nil
+}
@@ -0,0 +1,334 @@
+package scrapingo
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/andybalholm/cascadia"
+	"golang.org/x/net/html"
+)
+
+const (
+	formURLEncoded = "application/x-www-form-urlencoded"
+	formMultipart  = "multipart/form-data"
+)
+
+//表單中的欄位
+type FormField struct {
+	Name  string
+	Value string
+}
+
+//從HTML中取出的<form> 欄位依照頁面中的順序排列
+//包含hidden欄位(例如CSRF token) 以及select checkbox radio的默認值
+//<form>以外以form="id"指定該表單的欄位也會被取出 disabled的<fieldset>中的欄位不會被取出
+//修改欄位後調用Request初始化提交表單的Request 例：
+//  form, _ := resp.Form("form#login")
+//  form.Set("username", "user")
+//  form.Set("password", "pass")
+//  req, _ := form.Request(scrapingo.ParseFunction(ParseHome))
+type Form struct {
+	//已依照頁面URL解析的提交位置 <form>未設置action時為頁面URL
+	Action *url.URL
+	//大寫的提交方式 默認為GET
+	Method string
+	//application/x-www-form-urlencoded或multipart/form-data
+	Enctype string
+	Fields  []FormField
+
+	files []multipartFile
+}
+
+//返回欄位的第一個值 不存在時返回空字串
+func (f *Form) Get(name string) string {
+	for _, field := range f.Fields {
+		if field.Name == name {
+			return field.Value
+		}
+	}
+	return ""
+}
+
+//設置欄位的值 會取代同名的所有欄位 不存在時添加至最後
+func (f *Form) Set(name, value string) {
+	for i, field := range f.Fields {
+		if field.Name == name {
+			f.Fields[i].Value = value
+			f.Fields = append(f.Fields[:i+1], removeFields(f.Fields[i+1:], name)...)
+			return
+		}
+	}
+	f.Add(name, value)
+}
+
+//添加欄位 不會取代同名的欄位
+func (f *Form) Add(name, value string) {
+	f.Fields = append(f.Fields, FormField{Name: name, Value: value})
+}
+
+//刪除同名的所有欄位
+func (f *Form) Del(name string) {
+	f.Fields = removeFields(f.Fields, name)
+}
+
+func removeFields(fields []FormField, name string) []FormField {
+	kept := fields[:0]
+	for _, field := range fields {
+		if field.Name != name {
+			kept = append(kept, field)
+		}
+	}
+	return kept
+}
+
+//為<input type="file">添加檔案 會將Enctype改為multipart/form-data
+//需要POST的表單才會上傳檔案 (參考scrapingo.Multipart.AddFile)
+func (f *Form) AddFile(field, filename string, r io.Reader) error {
+	m := &Multipart{}
+	if err := m.AddFile(field, filename, r); err != nil {
+		return err
+	}
+	f.files = append(f.files, m.files...)
+	f.Enctype = formMultipart
+	return nil
+}
+
+//返回所有欄位
+func (f *Form) Values() url.Values {
+	val := url.Values{}
+	for _, field := range f.Fields {
+		val.Add(field.Name, field.Value)
+	}
+	return val
+}
+
+//依照欄位順序編碼為application/x-www-form-urlencoded
+func (f *Form) encode() string {
+	var buf strings.Builder
+	for i, field := range f.Fields {
+		if i > 0 {
+			buf.WriteByte('&')
+		}
+		buf.WriteString(url.QueryEscape(field.Name))
+		buf.WriteByte('=')
+		buf.WriteString(url.QueryEscape(field.Value))
+	}
+	return buf.String()
+}
+
+//依照Method以及Enctype初始化提交表單的Request
+//GET時欄位會取代Action的query POST時依照Enctype編碼至Body並設置Content-Type
+func (f *Form) Request(options ...RequestOption) (*Request, error) {
+	if f.Action == nil {
+		return nil, ErrURLMiss
+	}
+	action := *f.Action
+	action.Fragment = ""
+	if f.Method != http.MethodPost {
+		action.RawQuery = f.encode()
+	}
+	req, err := NewRequest(action.String(), options...)
+	if err != nil {
+		return nil, err
+	}
+	if f.Method != http.MethodPost {
+		req.Method = http.MethodGet
+		return req, nil
+	}
+
+	if f.Enctype == formMultipart {
+		m := &Multipart{fields: f.Fields, files: f.files}
+		MultipartBody(m)(req)
+		return req, nil
+	}
+	req.Method = http.MethodPost
+	req.Body = strings.NewReader(f.encode())
+	req.Header.Set("Content-Type", formURLEncoded)
+	return req, nil
+}
+
+//取出第一個符合CSS選擇器的<form> selector為空時取頁面中的第一個<form>
+//找不到時返回ErrFormNotFound
+func (r *Response) Form(selector string) (*Form, error) {
+	doc, err := html.Parse(bytes.NewReader(r.Body))
+	if err != nil {
+		return nil, err
+	}
+	if selector == "" {
+		selector = "form"
+	}
+	sel, err := cascadia.Compile(selector)
+	if err != nil {
+		return nil, err
+	}
+	for _, n := range sel.MatchAll(doc) {
+		if n.Data == "form" {
+			return parseForm(n, baseURL(doc, r.URL), r.URL), nil
+		}
+	}
+	return nil, ErrFormNotFound
+}
+
+//將該元素作為<form>解析 元素不是<form>時返回ErrFormNotFound
+func (e *HTMLElement) Form() (*Form, error) {
+	if e.DOM.Data != "form" {
+		return nil, ErrFormNotFound
+	}
+	return parseForm(e.DOM, e.base, e.Response.URL), nil
+}
+
+//依照瀏覽器的規則取出表單的默認值
+//disabled的欄位 未勾選的checkbox radio 以及按鈕不會被提交
+//disabled的<fieldset>中只有第一個<legend>內的欄位會被提交
+//<form>設置了id時 包含頁面中以form屬性指定該表單的欄位
+//action以base為基準解析 未設置action時為頁面URL
+func parseForm(n *html.Node, base, page *url.URL) *Form {
+	form := &Form{
+		Method:  strings.ToUpper(strings.TrimSpace(attrValue(n, "method"))),
+		Enctype: strings.ToLower(strings.TrimSpace(attrValue(n, "enctype"))),
+	}
+	if form.Method != http.MethodPost {
+		form.Method = http.MethodGet
+	}
+	if form.Enctype != formMultipart {
+		form.Enctype = formURLEncoded
+	}
+	form.Action = page
+	if action := strings.TrimSpace(attrValue(n, "action")); action != "" && base != nil {
+		if u, err := base.Parse(action); err == nil {
+			form.Action = u
+		}
+	}
+
+	//欄位設置了form屬性時 以form屬性決定所屬的表單
+	id := attrValue(n, "id")
+	owned := func(field *html.Node, inside bool) bool {
+		if hasAttr(field, "form") {
+			return id != "" && attrValue(field, "form") == id
+		}
+		return inside
+	}
+
+	var f func(*html.Node, bool)
+	f = func(node *html.Node, inside bool) {
+		inside = inside || node == n
+		if node.Type != html.ElementNode {
+			for c := node.FirstChild; c != nil; c = c.NextSibling {
+				f(c, inside)
+			}
+			return
+		}
+		if node.Data == "fieldset" && hasAttr(node, "disabled") {
+			for c := node.FirstChild; c != nil; c = c.NextSibling {
+				if c.Type == html.ElementNode && c.Data == "legend" {
+					f(c, inside)
+					break
+				}
+			}
+			return
+		}
+		name := attrValue(node, "name")
+		if name == "" || hasAttr(node, "disabled") || !owned(node, inside) {
+			if node.Data != "select" && node.Data != "textarea" {
+				for c := node.FirstChild; c != nil; c = c.NextSibling {
+					f(c, inside)
+				}
+			}
+			return
+		}
+		switch node.Data {
+		case "input":
+			switch strings.ToLower(attrValue(node, "type")) {
+			case "submit", "button", "reset", "image", "file":
+			case "checkbox", "radio":
+				if hasAttr(node, "checked") {
+					value := attrValue(node, "value")
+					if !hasAttr(node, "value") {
+						value = "on"
+					}
+					form.Add(name, value)
+				}
+			default:
+				form.Add(name, attrValue(node, "value"))
+			}
+		case "textarea":
+			form.Add(name, strings.TrimPrefix(nodeText(node), "\n"))
+		case "select":
+			for _, value := range selectValues(node) {
+				form.Add(name, value)
+			}
+		default:
+			for c := node.FirstChild; c != nil; c = c.NextSibling {
+				f(c, inside)
+			}
+		}
+	}
+
+	//依照欄位在頁面中的順序 設置了id時需遍歷整個頁面
+	root := n
+	if id != "" {
+		for root.Parent != nil {
+			root = root.Parent
+		}
+	}
+	f(root, false)
+	return form
+}
+
+//返回<select>所選擇的值 單選且沒有selected時為第一個option
+func selectValues(n *html.Node) []string {
+	var options []*html.Node
+	for _, option := range cascadia.MustCompile("option").MatchAll(n) {
+		if !hasAttr(option, "disabled") {
+			options = append(options, option)
+		}
+	}
+	var values []string
+	for _, option := range options {
+		if hasAttr(option, "selected") {
+			values = append(values, optionValue(option))
+		}
+	}
+	if len(values) == 0 && !hasAttr(n, "multiple") && len(options) > 0 {
+		values = append(values, optionValue(options[0]))
+	}
+	return values
+}
+
+//option未設置value時為其文字
+func optionValue(n *html.Node) string {
+	if hasAttr(n, "value") {
+		return attrValue(n, "value")
+	}
+	return strings.TrimSpace(nodeText(n))
+}
+
+func attrValue(n *html.Node, k string) string {
+	for _, attr := range n.Attr {
+		if attr.Key == k {
+			return attr.Val
+		}
+	}
+	return ""
+}
+
+func hasAttr(n *html.Node, k string) bool {
+	for _, attr := range n.Attr {
+		if attr.Key == k {
+			return true
+		}
+	}
+	return false
+}
+
+//提交表單 (參考scrapingo.Form)
+func (c *Collector) SubmitForm(f *Form, p ParseFunc) (*ParseResult, error) {
+	req, err := f.Request(ParseFunction(p))
+	if err != nil {
+		return nil, err
+	}
+	return c.Request(req)
+}
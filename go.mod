@@ -3,10 +3,16 @@ module github.com/Gaku0607/scrapingo
 go 1.15
 
 require (
+	github.com/andybalholm/brotli v1.0.6
+	github.com/andybalholm/cascadia v1.2.0
+	github.com/antchfx/htmlquery v1.2.3
+	github.com/antchfx/xmlquery v1.3.5
+	github.com/antchfx/xpath v1.1.10
 	github.com/go-sql-driver/mysql v1.5.0
 	github.com/gobwas/glob v0.2.3
 	github.com/gomodule/redigo v1.8.2
 	github.com/jinzhu/gorm v1.9.16
+	github.com/tidwall/gjson v1.6.3
 	golang.org/x/net v0.0.0-20201110031124-69a78807bb2b
 	golang.org/x/text v0.3.4
 )
@@ -0,0 +1,249 @@
+package scrapingo
+
+import (
+	"bytes"
+	"net/url"
+	"strings"
+
+	"github.com/andybalholm/cascadia"
+	"golang.org/x/net/html"
+)
+
+//當Response為HTML時 對符合CSS選擇器的每個元素調用
+type HTMLCallback func(*HTMLElement)
+
+//Id為自行定義的唯一識別調用 調用OnHTMLDetach刪除時所使用
+type HTMLCallbackContainer struct {
+	Id       int
+	Selector string
+	Func     HTMLCallback
+	sel      cascadia.Selector
+}
+
+//符合CSS選擇器的HTML元素
+type HTMLElement struct {
+	//元素的標籤名稱
+	Name string
+	//該元素在所有符合的元素中的位子
+	Index    int
+	Request  *Request
+	Response *Response
+	//元素所對應的節點
+	DOM *html.Node
+
+	//解析頁面中相對URL時的基準 有<base href>時以其為主
+	base   *url.URL
+	result *ParseResult
+}
+
+//初始化HTMLElement
+func newHTMLElement(resp *Response, base *url.URL, result *ParseResult, n *html.Node, index int) *HTMLElement {
+	return &HTMLElement{
+		Name:     n.Data,
+		Index:    index,
+		Request:  resp.Request,
+		Response: resp,
+		DOM:      n,
+		base:     base,
+		result:   result,
+	}
+}
+
+//返回元素中所有的文字
+func (e *HTMLElement) Text() string {
+	return nodeText(e.DOM)
+}
+
+//返回元素的屬性值 不存在時返回空字串
+func (e *HTMLElement) Attr(k string) string {
+	for _, attr := range e.DOM.Attr {
+		if attr.Key == k {
+			return attr.Val
+		}
+	}
+	return ""
+}
+
+//返回元素的HTML 包含元素本身
+func (e *HTMLElement) HTML() string {
+	buf := &bytes.Buffer{}
+	html.Render(buf, e.DOM)
+	return buf.String()
+}
+
+//返回第一個符合選擇器的子元素的文字
+func (e *HTMLElement) ChildText(selector string) string {
+	if n := e.findFirst(selector); n != nil {
+		return strings.TrimSpace(nodeText(n))
+	}
+	return ""
+}
+
+//返回所有符合選擇器的子元素的文字
+func (e *HTMLElement) ChildTexts(selector string) []string {
+	var texts []string
+	for _, n := range e.findAll(selector) {
+		texts = append(texts, strings.TrimSpace(nodeText(n)))
+	}
+	return texts
+}
+
+//返回第一個符合選擇器的子元素的屬性值
+func (e *HTMLElement) ChildAttr(selector, attr string) string {
+	if n := e.findFirst(selector); n != nil {
+		return (&HTMLElement{DOM: n}).Attr(attr)
+	}
+	return ""
+}
+
+//返回所有符合選擇器的子元素的屬性值
+func (e *HTMLElement) ChildAttrs(selector, attr string) []string {
+	var attrs []string
+	for _, n := range e.findAll(selector) {
+		attrs = append(attrs, (&HTMLElement{DOM: n}).Attr(attr))
+	}
+	return attrs
+}
+
+//對每個符合選擇器的子元素調用f
+func (e *HTMLElement) ForEach(selector string, f func(int, *HTMLElement)) {
+	for i, n := range e.findAll(selector) {
+		f(i, newHTMLElement(e.Response, e.base, e.result, n, i))
+	}
+}
+
+//將頁面中的相對URL轉為絕對URL 無法解析時返回空字串
+func (e *HTMLElement) AbsoluteURL(link string) string {
+	return absoluteURL(e.base, link)
+}
+
+//以頁面URL為基準初始化新的Request
+func (e *HTMLElement) NewRequest(link string, options ...RequestOption) (*Request, error) {
+	u := e.AbsoluteURL(link)
+	if u == "" {
+		return nil, ErrURLMiss
+	}
+	return NewRequest(u, options...)
+}
+
+//以頁面URL為基準初始化新的Request 並加入至ParseResult.Requests
+func (e *HTMLElement) Follow(link string, options ...RequestOption) error {
+	req, err := e.NewRequest(link, options...)
+	if err != nil {
+		return err
+	}
+	e.result.Requests = append(e.result.Requests, req)
+	return nil
+}
+
+//將item加入至ParseResult.Items
+func (e *HTMLElement) AddItem(item interface{}) {
+	e.result.Items = append(e.result.Items, item)
+}
+
+//以base為基準將相對URL轉為絕對URL 並移除Fragment
+//空字串以及只有Fragment的URL返回空字串
+func absoluteURL(base *url.URL, link string) string {
+	link = strings.TrimSpace(link)
+	if link == "" || strings.HasPrefix(link, "#") {
+		return ""
+	}
+	u, err := base.Parse(link)
+	if err != nil {
+		return ""
+	}
+	u.Fragment = ""
+	return u.String()
+}
+
+//返回所有符合選擇器的子元素 不包含元素本身
+func (e *HTMLElement) findAll(selector string) []*html.Node {
+	sel, err := cascadia.Compile(selector)
+	if err != nil {
+		return nil
+	}
+	var nodes []*html.Node
+	for c := e.DOM.FirstChild; c != nil; c = c.NextSibling {
+		nodes = append(nodes, sel.MatchAll(c)...)
+	}
+	return nodes
+}
+
+//返回第一個符合選擇器的子元素
+func (e *HTMLElement) findFirst(selector string) *html.Node {
+	sel, err := cascadia.Compile(selector)
+	if err != nil {
+		return nil
+	}
+	for c := e.DOM.FirstChild; c != nil; c = c.NextSibling {
+		if n := sel.MatchFirst(c); n != nil {
+			return n
+		}
+	}
+	return nil
+}
+
+//返回節點中所有的文字
+func nodeText(n *html.Node) string {
+	buf := &strings.Builder{}
+	var f func(*html.Node)
+	f = func(n *html.Node) {
+		if n.Type == html.TextNode {
+			buf.WriteString(n.Data)
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			f(c)
+		}
+	}
+	f(n)
+	return buf.String()
+}
+
+//判斷Response是否為HTML
+func isHTML(resp *Response) bool {
+	ct := resp.ContentType()
+	return ct == "text/html" || ct == "application/xhtml+xml"
+}
+
+//返回頁面中解析相對URL的基準 有<base href>時以其為主
+func baseURL(doc *html.Node, u *url.URL) *url.URL {
+	if n := cascadia.MustCompile("base[href]").MatchFirst(doc); n != nil {
+		if base, err := u.Parse((&HTMLElement{DOM: n}).Attr("href")); err == nil {
+			return base
+		}
+	}
+	return u
+}
+
+//Id為刪除時的唯一標示 設置HTMLCallback
+//Response為HTML時 會對每個符合CSS選擇器的元素調用所設置的HTMLCallback
+func (c *Collector) OnHTML(Id int, selector string, f HTMLCallback) error {
+	sel, err := cascadia.Compile(selector)
+	if err != nil {
+		return err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.htmlcallbacks = append(c.htmlcallbacks, HTMLCallbackContainer{Id: Id, Selector: selector, Func: f, sel: sel})
+	return nil
+}
+
+//輸入指定Id會刪除對應的HTMLCallback
+func (c *Collector) OnHTMLDetach(Id int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for index, callback := range c.htmlcallbacks {
+		if callback.Id == Id {
+			c.htmlcallbacks = append(c.htmlcallbacks[:index], c.htmlcallbacks[index+1:]...)
+		}
+	}
+}
+
+//將會調用自定義的HTMLCallback 結果會加入至result
+func (c *Collector) handleOnHTML(resp *Response, doc *html.Node, base *url.URL, result *ParseResult) {
+	for _, callback := range c.htmlcallbacks {
+		for i, n := range callback.sel.MatchAll(doc) {
+			callback.Func(newHTMLElement(resp, base, result, n, i))
+		}
+	}
+}
@@ -0,0 +1,134 @@
+package scrapingo
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+
+	"github.com/tidwall/gjson"
+)
+
+//將v轉為JSON作為Body進行POST Content-Type為application/json
+//要設置其他自定義標頭，請使用Do or Request。
+func (c *Collector) PostJSON(URL string, v interface{}, p ParseFunc) (*ParseResult, error) {
+	body, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	header := http.Header{
+		"Content-Type": {"application/json"},
+		"Accept":       {"application/json"},
+	}
+	return c.scrapingURL(URL, header, http.MethodPost, bytes.NewReader(body), nil, p)
+}
+
+//以GJSON的路徑語法查詢ResponsBody (例: data.items.#.url)
+//語法請參考 https://github.com/tidwall/gjson/blob/master/SYNTAX.md
+func (r *Response) JSONPath(path string) gjson.Result {
+	return gjson.GetBytes(r.Body, path)
+}
+
+//查詢路徑所對應的URL 以Response的URL為基準初始化Request
+//路徑的結果為陣列時 對每個字串初始化Request
+func (r *Response) JSONRequests(path string, options ...RequestOption) ([]*Request, error) {
+	if !gjson.ValidBytes(r.Body) {
+		return nil, ErrInvalidJSON
+	}
+	var reqs []*Request
+	var err error
+	forEachJSON(r.JSONPath(path), func(val gjson.Result) bool {
+		link := absoluteURL(r.URL, val.String())
+		if link == "" {
+			return true
+		}
+		var req *Request
+		if req, err = NewRequest(link, options...); err != nil {
+			return false
+		}
+		reqs = append(reqs, req)
+		return true
+	})
+	return reqs, err
+}
+
+//將ResponsBody解析至v
+func (r *Response) DecodeJSON(v interface{}) error {
+	return json.Unmarshal(r.Body, v)
+}
+
+//返回將ResponsBody解析為item的ParseFuncWithError
+//每次解析時會依照prototype的型別建立新的item 並加入至ParseResult.Items
+//path不為空時只解析路徑所對應的值 結果為陣列且prototype不為slice時 每個元素皆為一個item
+//prototype為struct指標時 item才能被賦予scrapingo.Model的值
+//prototype為nil或無法解析JSON的型別時 所返回的ParseFuncWithError皆返回錯誤
+func JSONParse(path string, prototype interface{}) ParseFuncWithError {
+	typ := reflect.TypeOf(prototype)
+	if typ == nil || !jsonPrototype(typ) {
+		err := fmt.Errorf("scrapingo: JSONParse requires a non-nil JSON decodable prototype, got %T", prototype)
+		return func(*Response) (*ParseResult, error) {
+			return nil, err
+		}
+	}
+	isPtr := typ.Kind() == reflect.Ptr
+	if isPtr {
+		typ = typ.Elem()
+	}
+
+	return func(resp *Response) (*ParseResult, error) {
+		if !gjson.ValidBytes(resp.Body) {
+			return nil, ErrInvalidJSON
+		}
+		raw := []gjson.Result{{Type: gjson.JSON, Raw: string(resp.Body)}}
+		if path != "" {
+			val := resp.JSONPath(path)
+			raw = []gjson.Result{val}
+			if val.IsArray() && typ.Kind() != reflect.Slice {
+				raw = val.Array()
+			}
+		}
+
+		result := &ParseResult{}
+		for _, val := range raw {
+			if !val.Exists() {
+				continue
+			}
+			v := reflect.New(typ)
+			if err := json.Unmarshal([]byte(val.Raw), v.Interface()); err != nil {
+				return nil, err
+			}
+			if isPtr {
+				result.Items = append(result.Items, v.Interface())
+			} else {
+				result.Items = append(result.Items, v.Elem().Interface())
+			}
+		}
+		return result, nil
+	}
+}
+
+//依序處理查詢結果 結果為陣列時處理每個元素
+func forEachJSON(val gjson.Result, f func(gjson.Result) bool) {
+	if val.IsArray() {
+		val.ForEach(func(_, v gjson.Result) bool {
+			return f(v)
+		})
+		return
+	}
+	if val.Exists() {
+		f(val)
+	}
+}
+
+//判斷該型別是否能作為JSON解析的目標
+func jsonPrototype(typ reflect.Type) bool {
+	if typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	switch typ.Kind() {
+	case reflect.Chan, reflect.Func, reflect.UnsafePointer, reflect.Complex64, reflect.Complex128:
+		return false
+	}
+	return true
+}
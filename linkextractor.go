@@ -0,0 +1,208 @@
+package scrapingo
+
+import (
+	"bytes"
+	"net/url"
+	"strings"
+
+	"github.com/andybalholm/cascadia"
+	"golang.org/x/net/html"
+)
+
+//URL符合Filter時 以Parse以及Options初始化Request Filter為nil時符合所有URL
+type LinkRule struct {
+	Filter  URLFilter
+	Parse   ParseFunc
+	Options []RequestOption
+}
+
+//依照規則從HTML中取出連結並轉為Request
+//添加至Collector或ConcurrentEngine後 所取出的Request會加入至ParseResult.Requests
+type LinkExtractor struct {
+	//URL需符合其中任一個URLFilter 為空時不進行限制
+	Allow []URLFilter
+	//符合其中任一個URLFilter的URL將被忽略 優先於Allow
+	Deny []URLFilter
+	//只從符合CSS選擇器的區域中取出連結 為空時為整個頁面
+	RestrictSelectors []string
+	//取出連結的標籤 為空時為a以及area
+	Tags []string
+	//取出連結的屬性 為空時為href
+	Attrs []string
+	//為true時不忽略rel="nofollow"的連結 以及<meta name="robots" content="nofollow">的頁面
+	FollowNofollow bool
+	//URL符合LinkRule時使用該規則的Parse以及Options 依序匹配
+	Rules []LinkRule
+	//URL不符合任何LinkRule時所使用的ParseFunc
+	Parse ParseFunc
+}
+
+//LinkExtractor未設置Tags以及Attrs時所使用的默認值
+var (
+	defaultLinkTags  = []string{"a", "area"}
+	defaultLinkAttrs = []string{"href"}
+)
+
+//初始化LinkExtractor 默認從a以及area的href取出連結
+func NewLinkExtractor() *LinkExtractor {
+	return &LinkExtractor{
+		Tags:  append([]string(nil), defaultLinkTags...),
+		Attrs: append([]string(nil), defaultLinkAttrs...),
+	}
+}
+
+//添加LinkRule 符合filter的URL將使用p進行解析 filter為nil時符合所有URL
+func (l *LinkExtractor) AddRule(filter URLFilter, p ParseFunc, options ...RequestOption) {
+	l.Rules = append(l.Rules, LinkRule{Filter: filter, Parse: p, Options: options})
+}
+
+//從HTML格式的ResponsBody中取出連結並轉為Request
+func (l *LinkExtractor) Extract(resp *Response) ([]*Request, error) {
+	doc, err := html.Parse(bytes.NewReader(resp.Body))
+	if err != nil {
+		return nil, err
+	}
+	return l.extract(doc, baseURL(doc, resp.URL))
+}
+
+func (l *LinkExtractor) extract(doc *html.Node, base *url.URL) ([]*Request, error) {
+	if !l.FollowNofollow && metaNofollow(doc) {
+		return nil, nil
+	}
+
+	roots := []*html.Node{doc}
+	if len(l.RestrictSelectors) > 0 {
+		roots = nil
+		for _, selector := range l.RestrictSelectors {
+			sel, err := cascadia.Compile(selector)
+			if err != nil {
+				return nil, err
+			}
+			roots = append(roots, sel.MatchAll(doc)...)
+		}
+	}
+
+	var reqs []*Request
+	seen := make(map[string]bool)
+	for _, root := range roots {
+		for _, link := range l.links(root, base) {
+			if seen[link] || !l.allowed(link) {
+				continue
+			}
+			seen[link] = true
+			req, err := l.newRequest(link)
+			if err != nil {
+				return nil, err
+			}
+			reqs = append(reqs, req)
+		}
+	}
+	return reqs, nil
+}
+
+//返回root中所有符合Tags以及Attrs的絕對URL Tags或Attrs為空時使用默認值
+func (l *LinkExtractor) links(root *html.Node, base *url.URL) []string {
+	tags, attrs := l.Tags, l.Attrs
+	if len(tags) == 0 {
+		tags = defaultLinkTags
+	}
+	if len(attrs) == 0 {
+		attrs = defaultLinkAttrs
+	}
+	var links []string
+	var f func(*html.Node)
+	f = func(n *html.Node) {
+		if n.Type == html.ElementNode && contains(tags, n.Data) &&
+			(l.FollowNofollow || !hasToken((&HTMLElement{DOM: n}).Attr("rel"), "nofollow")) {
+			for _, attr := range n.Attr {
+				if !contains(attrs, attr.Key) {
+					continue
+				}
+				if link := absoluteURL(base, attr.Val); link != "" {
+					links = append(links, link)
+				}
+			}
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			f(c)
+		}
+	}
+	f(root)
+	return links
+}
+
+//判斷URL是否符合Allow以及Deny 只接受http以及https
+func (l *LinkExtractor) allowed(link string) bool {
+	if !strings.HasPrefix(link, "http://") && !strings.HasPrefix(link, "https://") {
+		return false
+	}
+	if matchFilters(l.Deny, link) {
+		return false
+	}
+	return len(l.Allow) == 0 || matchFilters(l.Allow, link)
+}
+
+//依照第一個符合的LinkRule初始化Request
+func (l *LinkExtractor) newRequest(link string) (*Request, error) {
+	for _, rule := range l.Rules {
+		if rule.Filter == nil || rule.Filter.Match(link) {
+			return NewRequest(link, append([]RequestOption{ParseFunction(rule.Parse)}, rule.Options...)...)
+		}
+	}
+	return NewRequest(link, ParseFunction(l.Parse))
+}
+
+//頁面是否設置了<meta name="robots" content="nofollow">
+func metaNofollow(doc *html.Node) bool {
+	for _, n := range cascadia.MustCompile("meta[name]").MatchAll(doc) {
+		meta := &HTMLElement{DOM: n}
+		if !strings.EqualFold(meta.Attr("name"), "robots") {
+			continue
+		}
+		content := meta.Attr("content")
+		if hasToken(content, "nofollow") || hasToken(content, "none") {
+			return true
+		}
+	}
+	return false
+}
+
+//判斷以空白或逗號分隔的屬性值中是否包含token
+func hasToken(s, token string) bool {
+	for _, t := range strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return r == ' ' || r == ',' || r == '\t' || r == '\n'
+	}) {
+		if t == token {
+			return true
+		}
+	}
+	return false
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if strings.EqualFold(v, s) {
+			return true
+		}
+	}
+	return false
+}
+
+//添加LinkExtractor 每個HTML頁面中取出的連結會加入至ParseResult.Requests
+func (c *Collector) AddLinkExtractor(l *LinkExtractor) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.linkextractors = append(c.linkextractors, l)
+}
+
+//將會調用所有的LinkExtractor 結果會加入至result
+func (c *Collector) handleLinkExtractors(doc *html.Node, base *url.URL, result *ParseResult) error {
+	for _, l := range c.linkextractors {
+		reqs, err := l.extract(doc, base)
+		if err != nil {
+			return err
+		}
+		result.Requests = append(result.Requests, reqs...)
+	}
+	return nil
+}
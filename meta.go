@@ -0,0 +1,158 @@
+package scrapingo
+
+import (
+	"encoding/json"
+	"sort"
+	"sync"
+)
+
+//Request所攜帶的Key/Value資料 用於在爬取的過程中傳遞資料
+//ParseResult.Requests會繼承ParentRequest的Meta
+//繼承後與ParentRequest共用同一份資料 直到任一方寫入時才進行複製
+//可序列化為JSON 反序列化後數字皆為float64
+type Meta struct {
+	mu   sync.RWMutex
+	data map[string]interface{}
+	//data與其他Meta共用 寫入前需先複製
+	shared bool
+}
+
+//初始化空的Meta
+func NewMeta() *Meta {
+	return &Meta{data: make(map[string]interface{})}
+}
+
+//返回Key所對應的值 Meta為nil時返回false
+func (m *Meta) Get(key string) (interface{}, bool) {
+	if m == nil {
+		return nil, false
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	val, ok := m.data[key]
+	return val, ok
+}
+
+//返回Key所對應的字串 不存在或不是字串時返回空字串
+func (m *Meta) GetString(key string) string {
+	val, _ := m.Get(key)
+	s, _ := val.(string)
+	return s
+}
+
+//設置Key所對應的值
+func (m *Meta) Set(key string, val interface{}) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.own()
+	m.data[key] = val
+}
+
+//刪除Key所對應的值
+func (m *Meta) Delete(key string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.own()
+	delete(m.data, key)
+}
+
+//依照Key的順序遍歷所有值 f返回false時停止
+//遍歷的是調用時的複製 f中可以對m進行寫入
+func (m *Meta) Range(f func(key string, val interface{}) bool) {
+	if m == nil {
+		return
+	}
+	m.mu.RLock()
+	keys := make([]string, 0, len(m.data))
+	data := make(map[string]interface{}, len(m.data))
+	for key, val := range m.data {
+		keys = append(keys, key)
+		data[key] = val
+	}
+	m.mu.RUnlock()
+
+	sort.Strings(keys)
+	for _, key := range keys {
+		if !f(key, data[key]) {
+			return
+		}
+	}
+}
+
+//返回Meta中值的數量
+func (m *Meta) Len() int {
+	if m == nil {
+		return 0
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return len(m.data)
+}
+
+//寫入前確保data不與其他Meta共用
+func (m *Meta) own() {
+	if m.data == nil {
+		m.data = make(map[string]interface{})
+		return
+	}
+	if !m.shared {
+		return
+	}
+	data := make(map[string]interface{}, len(m.data))
+	for key, val := range m.data {
+		data[key] = val
+	}
+	m.data, m.shared = data, false
+}
+
+//返回與m共用資料的新Meta m為nil時返回nil
+func (m *Meta) inherit() *Meta {
+	if m == nil {
+		return nil
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.data == nil {
+		m.data = make(map[string]interface{})
+	}
+	m.shared = true
+	return &Meta{data: m.data, shared: true}
+}
+
+//返回繼承parent後的Meta child中已設置的值優先
+func inheritMeta(parent, child *Meta) *Meta {
+	if parent == nil || parent == child {
+		return child
+	}
+	meta := parent.inherit()
+	child.Range(func(key string, val interface{}) bool {
+		meta.Set(key, val)
+		return true
+	})
+	return meta
+}
+
+//實現json.Marshaler
+func (m *Meta) MarshalJSON() ([]byte, error) {
+	if m == nil {
+		return []byte("null"), nil
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if m.data == nil {
+		return []byte("{}"), nil
+	}
+	return json.Marshal(m.data)
+}
+
+//實現json.Unmarshaler
+func (m *Meta) UnmarshalJSON(b []byte) error {
+	data := make(map[string]interface{})
+	if err := json.Unmarshal(b, &data); err != nil {
+		return err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.data, m.shared = data, false
+	return nil
+}
@@ -0,0 +1,127 @@
+package scrapingo
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+//multipart/form-data的內容 檔案會在添加時讀取至記憶體
+//boundary由內容計算 相同的內容會產生相同的Body 因此可以進行去重以及重試 例：
+//  m := &scrapingo.Multipart{}
+//  m.AddField("q", "golang")
+//  if err := m.AddFileFromPath("image", "./cat.jpg"); err != nil {
+//      return err
+//  }
+//  c.PostMultipart("https://example.com/search", m, ParseResult)
+type Multipart struct {
+	fields []FormField
+	files  []multipartFile
+}
+
+type multipartFile struct {
+	field       string
+	filename    string
+	contentType string
+	data        []byte
+}
+
+//添加文字欄位
+func (m *Multipart) AddField(name, value string) {
+	m.fields = append(m.fields, FormField{Name: name, Value: value})
+}
+
+//從r讀取檔案內容 Content-Type依照filename的副檔名判斷 無法判斷時依照內容判斷
+func (m *Multipart) AddFile(field, filename string, r io.Reader) error {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	contentType := mime.TypeByExtension(filepath.Ext(filename))
+	if contentType == "" {
+		contentType = http.DetectContentType(data)
+	}
+	m.files = append(m.files, multipartFile{
+		field:       field,
+		filename:    filename,
+		contentType: contentType,
+		data:        data,
+	})
+	return nil
+}
+
+//讀取path的檔案 filename為檔案名稱
+func (m *Multipart) AddFileFromPath(field, path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return m.AddFile(field, filepath.Base(path), f)
+}
+
+var quoteEscaper = strings.NewReplacer("\\", "\\\\", `"`, "\\\"")
+
+//編碼為multipart/form-data 返回Body以及含有boundary的Content-Type
+func (m *Multipart) encode() ([]byte, string) {
+	buf := &bytes.Buffer{}
+	w := multipart.NewWriter(buf)
+	w.SetBoundary(m.boundary())
+	for _, field := range m.fields {
+		w.WriteField(field.Name, field.Value)
+	}
+	for _, file := range m.files {
+		h := make(textproto.MIMEHeader)
+		h.Set("Content-Disposition", fmt.Sprintf(`form-data; name="%s"; filename="%s"`,
+			quoteEscaper.Replace(file.field), quoteEscaper.Replace(file.filename)))
+		h.Set("Content-Type", file.contentType)
+		part, _ := w.CreatePart(h)
+		part.Write(file.data)
+	}
+	w.Close()
+	return buf.Bytes(), w.FormDataContentType()
+}
+
+//依照內容計算boundary
+func (m *Multipart) boundary() string {
+	h := sha1.New()
+	for _, field := range m.fields {
+		fmt.Fprintf(h, "%q=%q\n", field.Name, field.Value)
+	}
+	for _, file := range m.files {
+		fmt.Fprintf(h, "%q;%q;%q;%d\n", file.field, file.filename, file.contentType, len(file.data))
+		h.Write(file.data)
+	}
+	return "scrapingo" + hex.EncodeToString(h.Sum(nil))
+}
+
+//將Request設置為multipart/form-data的POST 並設置含有boundary的Content-Type
+//需在Header之後設置 否則Content-Type會被取代
+func MultipartBody(m *Multipart) RequestOption {
+	return func(r *Request) {
+		body, contentType := m.encode()
+		r.Method = http.MethodPost
+		r.Body = bytes.NewReader(body)
+		if r.Header == nil {
+			r.Header = http.Header{}
+		}
+		r.Header.Set("Content-Type", contentType)
+	}
+}
+
+//以multipart/form-data進行POST (參考scrapingo.Multipart)
+//要設置其他自定義標頭，請使用Request以及MultipartBody。
+func (c *Collector) PostMultipart(URL string, m *Multipart, p ParseFunc) (*ParseResult, error) {
+	body, contentType := m.encode()
+	return c.scrapingURL(URL, http.Header{"Content-Type": {contentType}}, http.MethodPost, bytes.NewReader(body), nil, p)
+}
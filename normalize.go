@@ -0,0 +1,140 @@
+package scrapingo
+
+import (
+	"net"
+	"net/url"
+	"sort"
+	"strings"
+
+	"golang.org/x/net/idna"
+)
+
+//在去重以及請求前統一URL的格式 可以參考CanonicalNormalizer的實現方式
+type URLNormalizer interface {
+
+	//返回格式化後的URL 不可修改傳入的URL
+
+	Normalize(*url.URL) (*url.URL, error)
+}
+
+//與idna.Lookup相同 但允許域名中包含底線等字元
+var idnaProfile = idna.New(idna.MapForLookup(), idna.BidiRule(), idna.StrictDomainName(false))
+
+//各Scheme的默認Port
+var defaultPorts = map[string]string{
+	"http":  "80",
+	"https": "443",
+}
+
+//實現URLNormalizer interface
+//排序Query 移除Fragment 轉換Scheme以及Host為小寫 將國際化域名轉為punycode
+//移除默認Port 並移除StripParams所列出的Query參數
+type CanonicalNormalizer struct {
+	//需移除的Query參數名稱 結尾為*時移除所有該前綴的參數 (例: utm_*)
+	StripParams []string
+	//為true時保留Fragment
+	KeepFragment bool
+}
+
+//傳入需移除的Query參數名稱初始化CanonicalNormalizer
+func NewCanonicalNormalizer(stripParams ...string) *CanonicalNormalizer {
+	return &CanonicalNormalizer{StripParams: stripParams}
+}
+
+//Collector默認所使用的URLNormalizer 會移除utm_*追蹤參數
+func defaultNormalizer() *CanonicalNormalizer {
+	return NewCanonicalNormalizer("utm_*")
+}
+
+//實現URLNormalizer interface的 Normalize(*url.URL) (*url.URL, error)
+func (n *CanonicalNormalizer) Normalize(u *url.URL) (*url.URL, error) {
+	nu := *u
+	if u.User != nil {
+		user := *u.User
+		nu.User = &user
+	}
+	nu.Scheme = strings.ToLower(nu.Scheme)
+
+	if nu.Host != "" {
+		host, port := strings.ToLower(nu.Hostname()), nu.Port()
+		if net.ParseIP(host) == nil {
+			ascii, err := idnaProfile.ToASCII(host)
+			if err != nil {
+				return nil, err
+			}
+			host = ascii
+		}
+		if port == defaultPorts[nu.Scheme] {
+			port = ""
+		}
+		if port != "" {
+			host = net.JoinHostPort(host, port)
+		} else if strings.Contains(host, ":") {
+			//IPv6
+			host = "[" + host + "]"
+		}
+		nu.Host = host
+		if nu.Path == "" {
+			nu.Path = "/"
+		}
+	}
+
+	nu.RawQuery = n.query(nu.RawQuery)
+	nu.ForceQuery = false
+	if !n.KeepFragment {
+		nu.Fragment, nu.RawFragment = "", ""
+	}
+	return &nu, nil
+}
+
+//移除StripParams所列出的參數後 依照參數名稱排序Query
+//保留參數原本的編碼方式 名稱相同的參數維持原本的順序
+func (n *CanonicalNormalizer) query(raw string) string {
+	if raw == "" {
+		return ""
+	}
+	type pair struct {
+		key string
+		raw string
+	}
+	var pairs []pair
+	for _, part := range strings.Split(raw, "&") {
+		if part == "" {
+			continue
+		}
+		key := part
+		if i := strings.Index(key, "="); i >= 0 {
+			key = key[:i]
+		}
+		if k, err := url.QueryUnescape(key); err == nil {
+			key = k
+		}
+		if n.strip(key) {
+			continue
+		}
+		pairs = append(pairs, pair{key: key, raw: part})
+	}
+	sort.SliceStable(pairs, func(i, j int) bool {
+		return pairs[i].key < pairs[j].key
+	})
+
+	parts := make([]string, len(pairs))
+	for i, p := range pairs {
+		parts[i] = p.raw
+	}
+	return strings.Join(parts, "&")
+}
+
+//判斷該參數是否需要移除
+func (n *CanonicalNormalizer) strip(key string) bool {
+	for _, param := range n.StripParams {
+		if strings.HasSuffix(param, "*") {
+			if strings.HasPrefix(key, strings.TrimSuffix(param, "*")) {
+				return true
+			}
+		} else if key == param {
+			return true
+		}
+	}
+	return false
+}
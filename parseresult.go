@@ -7,6 +7,34 @@ func NilParse(b []byte) *ParseResult {
 	return &ParseResult{}
 }
 
+//接收完整Response的解析格式
+//可取得StatusCode Header 以及重定向後的URL和發起請求的Request
+type ResponseParseFunc func(*Response) *ParseResult
+
+//將ParseFunc轉為ResponseParseFunc 只傳入解碼後的ResponsBody
+func (p ParseFunc) ToResponseParse() ResponseParseFunc {
+	if p == nil {
+		p = NilParse
+	}
+	return func(r *Response) *ParseResult {
+		return p(r.Body)
+	}
+}
+
+//可返回錯誤的解析格式 返回錯誤時將丟棄ParseResult
+//錯誤會交由Collector的ErrCallback以及Logger處理
+type ParseFuncWithError func(*Response) (*ParseResult, error)
+
+//將ResponseParseFunc轉為ParseFuncWithError 為nil時使用NilParse
+func (p ResponseParseFunc) ToParseWithError() ParseFuncWithError {
+	if p == nil {
+		p = ParseFunc(NilParse).ToResponseParse()
+	}
+	return func(r *Response) (*ParseResult, error) {
+		return p(r), nil
+	}
+}
+
 //解析後的結果
 //Items為解析後 自定義的返回值
 //Requests為解析後所返回的下次請求
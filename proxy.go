@@ -0,0 +1,187 @@
+package scrapingo
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+)
+
+//請求時選擇所使用的Proxy 可以參考ProxyPool的實現方式
+type ProxySwitcher interface {
+
+	//根據請求選擇所使用的Proxy 返回nil時不使用Proxy
+
+	Proxy(*http.Request) (*url.URL, error)
+
+	//回報使用該Proxy的請求結果 err為nil時表示請求成功
+
+	Report(*url.URL, error)
+}
+
+//ProxyPool選擇Proxy的方式
+type ProxyStrategy int
+
+const (
+	//依序輪流使用每個Proxy
+	RoundRobinProxy ProxyStrategy = iota
+	//隨機選擇Proxy
+	RandomProxy
+	//相同域名固定使用同一個Proxy 該Proxy被暫停時重新分配
+	StickyProxy
+)
+
+func (s ProxyStrategy) String() string {
+	switch s {
+	case RoundRobinProxy:
+		return "RoundRobin"
+	case RandomProxy:
+		return "Random"
+	case StickyProxy:
+		return "Sticky"
+	default:
+		return "Unknown"
+	}
+}
+
+//Proxy的使用狀態
+type proxyState struct {
+	URL *url.URL
+	//連續失敗次數
+	fails int
+	//暫停使用直到該時間
+	disabledUntil time.Time
+}
+
+//實現ProxySwitcher interface
+//連續失敗MaxFails次的Proxy會暫停使用DisableTime
+type ProxyPool struct {
+	//選擇Proxy的方式
+	Strategy ProxyStrategy
+	//連續失敗達到MaxFails次時暫停使用該Proxy 小於等於0時不暫停
+	MaxFails int
+	//Proxy暫停使用的時間
+	DisableTime time.Duration
+
+	proxies []*proxyState
+	//RoundRobin當前所使用的Proxy的位子
+	ptr int
+	//StickyProxy中域名所對應的Proxy
+	sticky map[string]*proxyState
+	mu     sync.Mutex
+}
+
+//傳入選擇方式以及Proxy的URL初始化ProxyPool
+//默認連續失敗3次時暫停使用該Proxy 1分鐘
+func NewProxyPool(strategy ProxyStrategy, proxies ...string) (*ProxyPool, error) {
+	if len(proxies) == 0 {
+		return nil, ErrNoProxy
+	}
+	p := &ProxyPool{
+		Strategy:    strategy,
+		MaxFails:    3,
+		DisableTime: time.Minute,
+		sticky:      make(map[string]*proxyState),
+	}
+	for _, proxy := range proxies {
+		u, err := url.Parse(proxy)
+		if err != nil {
+			return nil, err
+		}
+		p.proxies = append(p.proxies, &proxyState{URL: u})
+	}
+	return p, nil
+}
+
+//實現ProxySwitcher interface的 Proxy(*http.Request) (*url.URL, error)
+//所有Proxy皆暫停使用時返回ErrNoAvailableProxy
+func (p *ProxyPool) Proxy(req *http.Request) (*url.URL, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := time.Now()
+	var available []*proxyState
+	for _, proxy := range p.proxies {
+		if !proxy.disabledUntil.After(now) {
+			available = append(available, proxy)
+		}
+	}
+	if len(available) == 0 {
+		return nil, ErrNoAvailableProxy
+	}
+
+	switch p.Strategy {
+	case RandomProxy:
+		return available[rand.Intn(len(available))].URL, nil
+	case StickyProxy:
+		host := req.URL.Hostname()
+		if proxy, ok := p.sticky[host]; ok && !proxy.disabledUntil.After(now) {
+			return proxy.URL, nil
+		}
+		proxy := p.next(now)
+		p.sticky[host] = proxy
+		return proxy.URL, nil
+	default:
+		return p.next(now).URL, nil
+	}
+}
+
+//依序返回下一個可使用的Proxy
+func (p *ProxyPool) next(now time.Time) *proxyState {
+	for {
+		proxy := p.proxies[p.ptr]
+		p.ptr = (p.ptr + 1) % len(p.proxies)
+		if !proxy.disabledUntil.After(now) {
+			return proxy
+		}
+	}
+}
+
+//實現ProxySwitcher interface的 Report(*url.URL, error)
+//請求成功時重置失敗次數 連續失敗達到MaxFails時暫停使用該Proxy
+func (p *ProxyPool) Report(u *url.URL, err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, proxy := range p.proxies {
+		if proxy.URL.String() != u.String() {
+			continue
+		}
+		if err == nil {
+			proxy.fails = 0
+			return
+		}
+		proxy.fails++
+		if p.MaxFails > 0 && proxy.fails >= p.MaxFails {
+			proxy.fails = 0
+			proxy.disabledUntil = time.Now().Add(p.DisableTime)
+		}
+		return
+	}
+}
+
+func (p *ProxyPool) String() string {
+	return fmt.Sprintf(
+		"Strategy:%s ProxyCount:%d MaxFails:%d DisableTime:%.3fs",
+		p.Strategy, len(p.proxies), p.MaxFails, p.DisableTime.Seconds(),
+	)
+}
+
+//Context中保存所選擇的Proxy的Key
+type proxyContextKey struct{}
+
+//將所選擇的Proxy放入請求的Context中 交由http.Transport使用
+func withProxy(req *http.Request, proxy *url.URL) *http.Request {
+	return req.WithContext(context.WithValue(req.Context(), proxyContextKey{}, proxy))
+}
+
+//http.Transport的Proxy函式 使用Transfer所選擇的Proxy
+//請求未經過ProxySwitcher時使用環境變數中的Proxy
+func proxyFromContext(req *http.Request) (*url.URL, error) {
+	if proxy, ok := req.Context().Value(proxyContextKey{}).(*url.URL); ok {
+		return proxy, nil
+	}
+	return http.ProxyFromEnvironment(req)
+}
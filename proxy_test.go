@@ -0,0 +1,144 @@
+package scrapingo
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+//以httptest模擬的forward proxy 以絕對URL接收請求
+func newForwardProxy(t *testing.T, hits *int) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		if !r.URL.IsAbs() {
+			t.Errorf("proxy received non-absolute URL %q", r.RequestURI)
+		}
+		*hits++
+		fmt.Fprintf(rw, "via proxy %s", r.URL.Host)
+	}))
+}
+
+func TestProxiesForwardProxy(t *testing.T) {
+	var hits int
+	proxy := newForwardProxy(t, &hits)
+	defer proxy.Close()
+
+	pool, err := NewProxyPool(RoundRobinProxy, proxy.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := NewCollector(Proxies(pool))
+	var body, used string
+	req, _ := NewRequest("http://example.invalid/page", ResponseParseFunction(func(resp *Response) *ParseResult {
+		body = string(resp.Body)
+		if resp.Request.Proxy != nil {
+			used = resp.Request.Proxy.String()
+		}
+		return nil
+	}))
+	if _, err := c.Request(req); err != nil {
+		t.Fatal(err)
+	}
+	if hits != 1 || body != "via proxy example.invalid" {
+		t.Fatalf("hits = %d body = %q", hits, body)
+	}
+	if used != proxy.URL {
+		t.Fatalf("Request.Proxy = %q, want %s", used, proxy.URL)
+	}
+
+	//移除ProxySwitcher後不再經過Proxy
+	if err := c.SetProxies(nil); err != nil {
+		t.Fatal(err)
+	}
+	req, _ = NewRequest("http://127.0.0.1:1/", DontFilter(true))
+	if _, err := c.Request(req); err == nil {
+		t.Fatal("request without proxy should fail to connect")
+	}
+	if hits != 1 {
+		t.Fatalf("hits = %d after removing proxies", hits)
+	}
+}
+
+//http.RoundTripper但不是*http.Transport
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestProxiesUnsupportedTransport(t *testing.T) {
+	pool, _ := NewProxyPool(RoundRobinProxy, "http://127.0.0.1:1")
+
+	c := NewCollector()
+	c.transfer.Client.Transport = roundTripperFunc(http.DefaultTransport.RoundTrip)
+	if err := c.SetProxies(pool); !errors.Is(err, ErrUnsupportedTransport) {
+		t.Fatalf("SetProxies err = %v, want ErrUnsupportedTransport", err)
+	}
+
+	Proxies(pool)(c)
+	req, _ := NewRequest("http://example.invalid/")
+	if _, err := c.Request(req); !errors.Is(err, ErrUnsupportedTransport) {
+		t.Fatalf("Request err = %v, want ErrUnsupportedTransport", err)
+	}
+}
+
+func TestProxyPoolReport(t *testing.T) {
+	pool, _ := NewProxyPool(RoundRobinProxy, "http://proxy-a:8080", "http://proxy-b:8080")
+	pool.MaxFails = 2
+	pool.DisableTime = 50 * time.Millisecond
+	req, _ := http.NewRequest(http.MethodGet, "http://example.com/", nil)
+	a := pool.proxies[0].URL
+	fail := errors.New("dial failed")
+
+	//成功時重置失敗次數
+	pool.Report(a, fail)
+	pool.Report(a, nil)
+	pool.Report(a, fail)
+	var selected bool
+	for i := 0; i < 2; i++ {
+		u, err := pool.Proxy(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		selected = selected || u.String() == a.String()
+	}
+	if !selected {
+		t.Fatalf("proxy %s was disabled before MaxFails consecutive failures", a)
+	}
+
+	//連續失敗MaxFails次時暫停使用
+	pool.Report(a, fail)
+	for i := 0; i < 4; i++ {
+		u, err := pool.Proxy(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if u.String() == a.String() {
+			t.Fatalf("disabled proxy %s was selected", a)
+		}
+	}
+
+	//全部暫停時返回ErrNoAvailableProxy
+	b := pool.proxies[1].URL
+	pool.Report(b, fail)
+	pool.Report(b, fail)
+	if _, err := pool.Proxy(req); !errors.Is(err, ErrNoAvailableProxy) {
+		t.Fatalf("err = %v, want ErrNoAvailableProxy", err)
+	}
+
+	//DisableTime之後重新使用
+	time.Sleep(pool.DisableTime)
+	seen := map[string]bool{}
+	for i := 0; i < 2; i++ {
+		u, err := pool.Proxy(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		seen[u.String()] = true
+	}
+	if !seen[a.String()] || !seen[b.String()] {
+		t.Fatalf("proxies after DisableTime = %v", seen)
+	}
+}
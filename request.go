@@ -45,6 +45,74 @@ func ParseFunction(f ParseFunc) RequestOption {
 	}
 }
 
+//修改Request默認的ErrPageParse
+//當StatusCode不被Collector接受時 使用該函式解析錯誤頁面
+func ErrPageParseFunction(f ResponseParseFunc) RequestOption {
+	return func(r *Request) {
+		r.ErrPageParse = f
+	}
+}
+
+//為true時該Request不使用Collector的DiskCache
+func NoCache(b bool) RequestOption {
+	return func(r *Request) {
+		r.NoCache = b
+	}
+}
+
+//指定ResponsBody的編碼 設置時優先於Collector.Encoding
+//名稱請參考 https://encoding.spec.whatwg.org
+func Encoding(name string) RequestOption {
+	return func(r *Request) {
+		r.Encoding = name
+	}
+}
+
+//為true時不對ResponsBody進行charset解碼 適用於圖片 PDF等二進位內容
+func Raw(b bool) RequestOption {
+	return func(r *Request) {
+		r.Raw = b
+	}
+}
+
+//將ResponsBody直接寫入檔案 (參考scrapingo.Download)
+func SaveTo(d *Download) RequestOption {
+	return func(r *Request) {
+		r.Download = d
+	}
+}
+
+//為true時該Request不進行去重 適用於需重複請求的頁面
+func DontFilter(b bool) RequestOption {
+	return func(r *Request) {
+		r.DontFilter = b
+	}
+}
+
+//設置Request的Meta 可重複使用設置多個值
+func MetaValue(key string, val interface{}) RequestOption {
+	return func(r *Request) {
+		if r.Meta == nil {
+			r.Meta = NewMeta()
+		}
+		r.Meta.Set(key, val)
+	}
+}
+
+//修改Request默認的ParseFuncWithError 設置時優先於ResponseParseFunc以及ParseFunc
+func ParseFunctionWithError(f ParseFuncWithError) RequestOption {
+	return func(r *Request) {
+		r.ParseWithError = f
+	}
+}
+
+//修改Request默認的ResponseParseFunc 設置時優先於ParseFunc
+func ResponseParseFunction(f ResponseParseFunc) RequestOption {
+	return func(r *Request) {
+		r.ResponseParse = f
+	}
+}
+
 //請求時所需要的URL 以及 URL所對應的解析函式
 type Request struct {
 	ID     int64 //Request的唯一識別
@@ -55,6 +123,52 @@ type Request struct {
 	Method string
 	Body   io.Reader
 	Parse  ParseFunc
+	//接收Response的解析函式 設置時優先於Parse
+	ResponseParse ResponseParseFunc
+	//可返回錯誤的解析函式 設置時優先於ResponseParse以及Parse
+	ParseWithError ParseFuncWithError
+	//StatusCode不被接受時用於解析錯誤頁面的函式 為nil時不進行解析
+	ErrPageParse ResponseParseFunc
+	//當前為第幾次請求 發生重試時會遞增 (請參考scrapingo.RetryPolicy)
+	Attempt int
+	//最後一次請求所使用的Proxy 未使用Proxy時為nil
+	Proxy *url.URL
+	//去重以及緩存時所使用的請求指紋 調用checkRequestInfo()後設置
+	Fingerprint uint64
+	//為true時不使用Collector的DiskCache
+	NoCache bool
+	//為true時不進行去重 也不會記錄至VisitStorage
+	DontFilter bool
+	//使用DiskCache時的緩存結果 (參考scrapingo.CacheHit)
+	CacheStatus string
+	//ResponsBody的編碼 為空時依照Content-Type進行探測
+	Encoding string
+	//為true時不對ResponsBody進行charset解碼
+	Raw bool
+	//設置時ResponsBody將直接寫入檔案 Response.Body為nil
+	Download *Download
+	//在爬取的過程中傳遞的資料 ParseResult.Requests會繼承ParentRequest的Meta
+	Meta *Meta
+}
+
+//返回Request所使用的解析函式
+//依序為ParseWithError ResponseParse Parse 皆未設置時使用NilParse
+func (r *Request) parseFunc() ParseFuncWithError {
+	if r.ParseWithError != nil {
+		return r.ParseWithError
+	}
+	if r.ResponseParse != nil {
+		return r.ResponseParse.ToParseWithError()
+	}
+	return r.Parse.ToResponseParse().ToParseWithError()
+}
+
+//複製Request 複製後的Header以及Meta與原Request互不影響
+func (r *Request) copy() *Request {
+	req := *r
+	req.Header = r.Header.Clone()
+	req.Meta = r.Meta.inherit()
+	return &req
 }
 
 func (r *Request) New(u string) (*Request, error) {
@@ -68,6 +182,14 @@ func (r *Request) New(u string) (*Request, error) {
 		Header: r.Header,
 		Body:   r.Body,
 		Parse:  r.Parse,
+
+		ResponseParse:  r.ResponseParse,
+		ParseWithError: r.ParseWithError,
+		ErrPageParse:   r.ErrPageParse,
+		NoCache:        r.NoCache,
+		Encoding:       r.Encoding,
+		Raw:            r.Raw,
+		Meta:           r.Meta.inherit(),
 	}, nil
 }
 func NewRequest(u string, options ...RequestOption) (*Request, error) {
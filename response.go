@@ -0,0 +1,23 @@
+package scrapingo
+
+import (
+	"net/http"
+	"net/url"
+)
+
+//請求完成後所返回的內容
+//Body為解碼後的ResponsBody RawBody為未經解碼的原始ResponsBody
+type Response struct {
+	//發起請求的Request
+	Request *Request
+	//HTTP StatusCode
+	StatusCode int
+	//Respons Header
+	Header http.Header
+	//經過重定向後最終的URL
+	URL *url.URL
+	//解碼後的ResponsBody
+	Body []byte
+	//未經解碼的ResponsBody
+	RawBody []byte
+}
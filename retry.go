@@ -0,0 +1,150 @@
+package scrapingo
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"syscall"
+	"time"
+)
+
+//RetryPolicy未設置StatusCodes時默認進行重試的StatusCode
+var DefaultRetryStatusCodes = []int{
+	http.StatusRequestTimeout,
+	http.StatusTooManyRequests,
+	http.StatusInternalServerError,
+	http.StatusBadGateway,
+	http.StatusServiceUnavailable,
+	http.StatusGatewayTimeout,
+}
+
+//請求發生網路錯誤 或是返回指定的StatusCode時進行重試
+//第n次重試前的等待時間為 Backoff * 2^(n-1) 並追加Jitter比例的隨機時間
+type RetryPolicy struct {
+	//最大請求次數(包含第一次請求) 小於等於1時不進行重試
+	MaxAttempts int
+	//第一次重試前的等待時間
+	Backoff time.Duration
+	//重試等待時間的上限 為0時則沒有上限
+	MaxBackoff time.Duration
+	//隨機等待時間佔Backoff的比例 範圍為0~1
+	Jitter float64
+	//需要進行重試的StatusCode 為nil時使用DefaultRetryStatusCodes
+	StatusCodes []int
+	//為true時Respons帶有Retry-After 則以Retry-After作為等待時間
+	RespectRetryAfter bool
+}
+
+//scrapingo默認使用的RetryPolicy
+//最多請求3次 等待時間由1秒開始 最長30秒
+func DefaultRetryPolicy() *RetryPolicy {
+	return &RetryPolicy{
+		MaxAttempts:       3,
+		Backoff:           time.Second,
+		MaxBackoff:        30 * time.Second,
+		Jitter:            0.2,
+		RespectRetryAfter: true,
+	}
+}
+
+//判斷該StatusCode是否需要重試
+func (p *RetryPolicy) retryStatus(code int) bool {
+	codes := p.StatusCodes
+	if codes == nil {
+		codes = DefaultRetryStatusCodes
+	}
+	for _, c := range codes {
+		if c == code {
+			return true
+		}
+	}
+	return false
+}
+
+//判斷第attempt次請求的結果是否需要進行重試
+func (p *RetryPolicy) retry(attempt int, resp *Response, err error) bool {
+	if p == nil || attempt >= p.MaxAttempts {
+		return false
+	}
+	if err != nil {
+		return retryableErr(err)
+	}
+	return p.retryStatus(resp.StatusCode)
+}
+
+//判斷該錯誤是否為暫時性的網路錯誤 其他錯誤(認證失敗 沒有可用的Proxy 解壓縮失敗等)不進行重試
+func retryableErr(err error) bool {
+	if errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, syscall.EPIPE) {
+		return true
+	}
+	//http.Client返回的錯誤皆為*url.Error 需判斷其中的錯誤
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		if urlErr.Err == io.EOF {
+			return true
+		}
+		err = urlErr.Err
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
+
+//第attempt次請求失敗後 距離下次請求的等待時間
+func (p *RetryPolicy) backoff(attempt int, resp *Response) time.Duration {
+	if p.RespectRetryAfter && resp != nil {
+		if d, ok := parseRetryAfter(resp.Header.Get("Retry-After")); ok {
+			return d
+		}
+	}
+	d := p.Backoff
+	for i := 1; i < attempt; i++ {
+		d *= 2
+		if p.MaxBackoff > 0 && d > p.MaxBackoff {
+			break
+		}
+	}
+	if p.Jitter > 0 && d > 0 {
+		d += time.Duration(rand.Int63n(int64(float64(d)*p.Jitter) + 1))
+	}
+	if p.MaxBackoff > 0 && d > p.MaxBackoff {
+		d = p.MaxBackoff
+	}
+	return d
+}
+
+//解析Retry-After 支持秒數以及HTTP-date兩種格式
+func parseRetryAfter(v string) (time.Duration, bool) {
+	if v == "" {
+		return 0, false
+	}
+	if sec, err := strconv.Atoi(v); err == nil {
+		if sec < 0 {
+			sec = 0
+		}
+		return time.Duration(sec) * time.Second, true
+	}
+	t, err := http.ParseTime(v)
+	if err != nil {
+		return 0, false
+	}
+	d := time.Until(t)
+	if d < 0 {
+		d = 0
+	}
+	return d, true
+}
+
+func (p *RetryPolicy) String() string {
+	return fmt.Sprintf(
+		"MaxAttempts:%d Backoff:%.3fs MaxBackoff:%.3fs Jitter:%.2f RespectRetryAfter:%v",
+		p.MaxAttempts, p.Backoff.Seconds(), p.MaxBackoff.Seconds(), p.Jitter, p.RespectRetryAfter,
+	)
+}
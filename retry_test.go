@@ -0,0 +1,53 @@
+package scrapingo
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+//返回最後一次請求的Request.Attempt以及錯誤
+func requestAttempt(c *Collector, URL string) (int, error) {
+	var attempt int
+	c.OnErr(0, func(req *Request, err error) {
+		attempt = req.Attempt
+	})
+	req, _ := NewRequest(URL)
+	_, err := c.Request(req)
+	return attempt, err
+}
+
+func TestRetryAuthErrorNotRetried(t *testing.T) {
+	token := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		rw.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer token.Close()
+
+	c := NewCollector(Retry(&RetryPolicy{MaxAttempts: 3, Backoff: time.Millisecond}))
+	c.AddAuthenticator("*", &ClientCredentials{TokenURL: token.URL})
+	attempt, err := requestAttempt(c, "http://example.invalid/")
+	var authErr *AuthError
+	if !errors.As(err, &authErr) {
+		t.Fatalf("err = %v, want *AuthError", err)
+	}
+	if attempt != 1 {
+		t.Fatalf("Attempt = %d, want 1", attempt)
+	}
+}
+
+func TestRetryNetworkError(t *testing.T) {
+	srv := httptest.NewServer(http.NotFoundHandler())
+	URL := srv.URL
+	srv.Close()
+
+	c := NewCollector(Retry(&RetryPolicy{MaxAttempts: 3, Backoff: time.Millisecond}))
+	attempt, err := requestAttempt(c, URL)
+	if err == nil {
+		t.Fatal("request to a closed server should fail")
+	}
+	if attempt != 3 {
+		t.Fatalf("Attempt = %d, want 3", attempt)
+	}
+}
@@ -0,0 +1,302 @@
+package scrapingo
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gobwas/glob"
+)
+
+//robots.txt讀取的最大Size
+const maxRobotsSize = 500 * 1024
+
+//robots.txt中的Allow或Disallow規則
+type robotsRule struct {
+	allow   bool
+	pattern string
+}
+
+//robots.txt中適用於指定User-Agent的規則
+type robotsGroup struct {
+	agents     []string
+	rules      []robotsRule
+	crawlDelay time.Duration
+	hasDelay   bool
+}
+
+//解析後的robots.txt
+type RobotsData struct {
+	groups []*robotsGroup
+	//robots.txt中所列出的Sitemap
+	Sitemaps []string
+}
+
+//解析robots.txt 無法辨識的行將被忽略
+func ParseRobots(body []byte) *RobotsData {
+	r := &RobotsData{}
+	var group *robotsGroup
+	//上一行是否為User-agent 連續的User-agent屬於同一個group
+	var lastAgent bool
+
+	scanner := bufio.NewScanner(bytes.NewReader(body))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if i := strings.Index(line, "#"); i >= 0 {
+			line = line[:i]
+		}
+		i := strings.Index(line, ":")
+		if i < 0 {
+			continue
+		}
+		key := strings.ToLower(strings.TrimSpace(line[:i]))
+		val := strings.TrimSpace(line[i+1:])
+
+		switch key {
+		case "user-agent":
+			if group == nil || !lastAgent {
+				group = &robotsGroup{}
+				r.groups = append(r.groups, group)
+			}
+			group.agents = append(group.agents, strings.ToLower(val))
+			lastAgent = true
+			continue
+		case "allow", "disallow":
+			//空的Disallow表示允許全部
+			if group != nil && val != "" {
+				group.rules = append(group.rules, robotsRule{allow: key == "allow", pattern: val})
+			}
+		case "crawl-delay":
+			if sec, err := strconv.ParseFloat(val, 64); group != nil && err == nil && sec >= 0 {
+				group.crawlDelay = time.Duration(sec * float64(time.Second))
+				group.hasDelay = true
+			}
+		case "sitemap":
+			if val != "" {
+				r.Sitemaps = append(r.Sitemaps, val)
+			}
+		}
+		lastAgent = false
+	}
+	return r
+}
+
+//返回適用於該User-Agent的group
+//優先使用名稱最長且包含於User-Agent中的group 其次為*
+func (r *RobotsData) group(agent string) *robotsGroup {
+	agent = strings.ToLower(agent)
+	var match, wildcard *robotsGroup
+	var length int
+	for _, g := range r.groups {
+		for _, a := range g.agents {
+			if a == "*" {
+				if wildcard == nil {
+					wildcard = g
+				}
+				continue
+			}
+			if a != "" && strings.Contains(agent, a) && len(a) > length {
+				match, length = g, len(a)
+			}
+		}
+	}
+	if match != nil {
+		return match
+	}
+	return wildcard
+}
+
+//判斷該User-Agent是否允許訪問path(包含Query)
+//以最長的匹配規則為準 長度相同時Allow優先
+func (r *RobotsData) Allowed(agent, path string) bool {
+	g := r.group(agent)
+	if g == nil {
+		return true
+	}
+	allow, length := true, -1
+	for _, rule := range g.rules {
+		if !robotsMatch(rule.pattern, path) {
+			continue
+		}
+		if l := len(rule.pattern); l > length || (l == length && rule.allow) {
+			allow, length = rule.allow, l
+		}
+	}
+	return allow
+}
+
+//返回該User-Agent的Crawl-delay 未設置時返回false
+func (r *RobotsData) CrawlDelay(agent string) (time.Duration, bool) {
+	g := r.group(agent)
+	if g == nil {
+		return 0, false
+	}
+	return g.crawlDelay, g.hasDelay
+}
+
+//判斷path是否符合robots.txt的規則 支持*以及結尾的$
+func robotsMatch(pattern, path string) bool {
+	end := strings.HasSuffix(pattern, "$")
+	if end {
+		pattern = strings.TrimSuffix(pattern, "$")
+	}
+	parts := strings.Split(pattern, "*")
+
+	if !strings.HasPrefix(path, parts[0]) {
+		return false
+	}
+	pos := len(parts[0])
+	for i, part := range parts[1:] {
+		//有$時最後一段必須位於結尾
+		if end && i == len(parts)-2 {
+			return strings.HasSuffix(path[pos:], part)
+		}
+		j := strings.Index(path[pos:], part)
+		if j < 0 {
+			return false
+		}
+		pos += j + len(part)
+	}
+	return !end || pos == len(path)
+}
+
+//每個Host所對應的robots.txt
+type robotsEntry struct {
+	data *RobotsData
+	err  error
+	//讀取完成時關閉
+	ready chan struct{}
+}
+
+//以Host為單位緩存robots.txt
+type robotsCache struct {
+	mu    sync.Mutex
+	hosts map[string]*robotsEntry
+}
+
+func newRobotsCache() *robotsCache {
+	return &robotsCache{hosts: make(map[string]*robotsEntry)}
+}
+
+//取得該URL的Host所對應的robots.txt 同一個Host只會讀取一次
+//讀取失敗時不進行緩存 下次請求時重新讀取
+//等待其他請求讀取時 ctx被取消則返回ctx.Err()
+//first為true時表示此次調用實際讀取了robots.txt
+func (r *robotsCache) get(ctx context.Context, u *url.URL, fetch func(robotsURL string) (*RobotsData, error)) (data *RobotsData, first bool, err error) {
+	key := u.Scheme + "://" + u.Host
+
+	r.mu.Lock()
+	entry, ok := r.hosts[key]
+	if !ok {
+		entry = &robotsEntry{ready: make(chan struct{})}
+		r.hosts[key] = entry
+	}
+	r.mu.Unlock()
+
+	if ok {
+		select {
+		case <-entry.ready:
+			return entry.data, false, entry.err
+		case <-ctx.Done():
+			return nil, false, ctx.Err()
+		}
+	}
+
+	entry.data, entry.err = fetch(key + "/robots.txt")
+	if entry.err != nil {
+		r.mu.Lock()
+		delete(r.hosts, key)
+		r.mu.Unlock()
+	}
+	close(entry.ready)
+	return entry.data, true, entry.err
+}
+
+//經由Transfer讀取robots.txt 與其他請求相同受到Proxy Limiter RetryPolicy以及Auth的影響
+//使用req的Context以及agent作為User-Agent
+//StatusCode為4xx時視為允許全部 5xx時返回錯誤
+func (c *Collector) fetchRobots(req *Request, robotsURL, agent string) (*RobotsData, error) {
+	r, err := NewRequest(robotsURL)
+	if err != nil {
+		return nil, err
+	}
+	r.Method = http.MethodGet
+	r.Ctx = req.Ctx
+	if agent != "" {
+		r.Header.Set("User-Agent", agent)
+	}
+	httpReq, err := http.NewRequestWithContext(r.Ctx, r.Method, robotsURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header = r.Header.Clone()
+
+	resp, err := c.transfer.do(r, httpReq, maxRobotsSize)
+	if resp == nil {
+		return nil, err
+	}
+	switch {
+	case resp.StatusCode >= 500:
+		if err == nil {
+			err = &HTTPStatusError{StatusCode: resp.StatusCode, Header: resp.Header, Body: resp.Body, URL: resp.URL}
+		}
+		return nil, err
+	case resp.StatusCode >= 400:
+		return &RobotsData{}, nil
+	}
+	return ParseRobots(resp.Body), nil
+}
+
+//確認robots.txt是否允許訪問該Request
+//robots.txt讀取失敗時返回包裝了ErrRobotsTxtDisallowed以及失敗原因的error
+//第一次讀取該Host的robots.txt時 若有設置Crawl-delay則自動註冊對應的Limiter
+func (c *Collector) checkRobots(req *Request) error {
+	if req.URL.Path == "/robots.txt" {
+		return nil
+	}
+	agent := req.Header.Get("User-Agent")
+	if agent == "" {
+		agent = c.UserAgent
+	}
+
+	data, first, err := c.robots.get(req.Ctx, req.URL, func(robotsURL string) (*RobotsData, error) {
+		return c.fetchRobots(req, robotsURL, agent)
+	})
+	if err != nil {
+		//請求被取消時直接返回Context的錯誤
+		if ctxErr := req.Ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+		return fmt.Errorf("%w: %v", ErrRobotsTxtDisallowed, err)
+	}
+	if first {
+		if delay, ok := data.CrawlDelay(agent); ok {
+			c.registerCrawlDelay(req.URL, delay)
+		}
+	}
+	if !data.Allowed(agent, req.URL.RequestURI()) {
+		return ErrRobotsTxtDisallowed
+	}
+	return nil
+}
+
+//為該Host註冊延遲時間為Crawl-delay的Limiter
+//已有Limiter匹配該Host時不進行註冊
+func (c *Collector) registerCrawlDelay(u *url.URL, delay time.Duration) {
+	host := u.Scheme + "://" + u.Host
+	if c.transfer.getLimiter(host+"/") != nil {
+		return
+	}
+	c.transfer.AddLimiter(&Limiter{
+		DelayTime:     delay,
+		Parallelcount: 1,
+		DomainGlob:    glob.QuoteMeta(host) + "/*",
+	})
+}
@@ -0,0 +1,50 @@
+package scrapingo
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestRobotsTxtUsesProxy(t *testing.T) {
+	var hits int
+	proxy := newForwardProxy(t, &hits)
+	defer proxy.Close()
+
+	pool, _ := NewProxyPool(RoundRobinProxy, proxy.URL)
+	c := NewCollector(Proxies(pool), RobotsTxt(true))
+	req, _ := NewRequest("http://example.invalid/page")
+	if _, err := c.Request(req); err != nil {
+		t.Fatal(err)
+	}
+	//robots.txt以及頁面皆經過Proxy
+	if hits != 2 {
+		t.Fatalf("proxy hits = %d, want 2", hits)
+	}
+}
+
+func TestRobotsTxtContextCancel(t *testing.T) {
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer srv.Close()
+	defer close(release)
+
+	c := NewCollector(RobotsTxt(true))
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	req, _ := NewRequest(srv.URL+"/page", Ctx(ctx))
+
+	start := time.Now()
+	_, err := c.Request(req)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("err = %v, want context.DeadlineExceeded", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("canceled request took %v", elapsed)
+	}
+}
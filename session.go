@@ -0,0 +1,215 @@
+package scrapingo
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/gobwas/glob"
+	"github.com/gobwas/glob/match"
+)
+
+//登入函式 c為登入專用的Collector 不進行去重也不受Session影響
+//登入所得的Cookie需保存在共用的CookieStorage中 (參考CollectorOption CookieJar) 例：
+//  func(c *scrapingo.Collector) error {
+//      var form *scrapingo.Form
+//      req, _ := scrapingo.NewRequest("https://example.com/login",
+//          scrapingo.ResponseParseFunction(func(resp *scrapingo.Response) *scrapingo.ParseResult {
+//              form, _ = resp.Form("form#login")
+//              return nil
+//          }))
+//      if _, err := c.Request(req); err != nil {
+//          return err
+//      }
+//      form.Set("username", "user")
+//      form.Set("password", "pass")
+//      _, err := c.SubmitForm(form, nil)
+//      return err
+//  }
+type LoginFunc func(c *Collector) error
+
+//需要登入的網站 URL符合DomainGlob時
+//在第一次請求前調用Login 並在Response判斷為Session過期時重新登入後重新請求一次
+//重新登入時 其他符合DomainGlob的請求會等待登入完成
+type Session struct {
+	//匹配URL的glob 例: *example.com/*
+	DomainGlob string
+	Login      LoginFunc
+	//判斷Session過期的StatusCode 例: 401 403
+	ExpiredStatus []int
+	//Response的URL以LoginURL開頭時判斷為過期 用於被重定向至登入頁面的情況
+	//以/開頭時只比對URL的Path
+	LoginURL string
+	//ResponsBody中包含Marker時判斷為過期 例: "請先登入"
+	Marker string
+	//自定義的判斷 返回true時判斷為過期
+	Expired func(*Response) bool
+
+	urlGlob glob.Glob
+	rw      sync.RWMutex
+	//已成功登入
+	loggedIn bool
+	//登入的次數 避免多個過期的請求重複登入
+	generation int
+}
+
+//初始化Session
+func (s *Session) register() (err error) {
+	s.urlGlob, err = glob.Compile(s.DomainGlob)
+	if err != nil {
+		return err
+	}
+	if _, ok := s.urlGlob.(match.Nothing); ok {
+		return ErrNoDomainPattern
+	}
+	return nil
+}
+
+func (s *Session) Match(URL string) bool {
+	return s.urlGlob.Match(URL)
+}
+
+//確認已登入 尚未登入時進行登入 返回當前的登入次數
+//登入中時會等待登入完成
+func (s *Session) ensure(c *Collector) (int, error) {
+	s.rw.RLock()
+	loggedIn, generation := s.loggedIn, s.generation
+	s.rw.RUnlock()
+	if loggedIn {
+		return generation, nil
+	}
+
+	s.rw.Lock()
+	defer s.rw.Unlock()
+	if !s.loggedIn {
+		if err := s.login(c); err != nil {
+			return 0, err
+		}
+	}
+	return s.generation, nil
+}
+
+//Session過期時重新登入 generation不同時代表其他請求已重新登入
+func (s *Session) relogin(c *Collector, generation int) error {
+	s.rw.Lock()
+	defer s.rw.Unlock()
+	if s.loggedIn && s.generation != generation {
+		return nil
+	}
+	return s.login(c)
+}
+
+//調用Login 需持有寫鎖
+func (s *Session) login(c *Collector) error {
+	s.loggedIn = false
+	if err := s.Login(c.loginCollector()); err != nil {
+		return fmt.Errorf("scrapingo: session login failed: %w", err)
+	}
+	s.loggedIn = true
+	s.generation++
+	return nil
+}
+
+//判斷Response是否代表Session過期
+func (s *Session) expired(resp *Response) bool {
+	for _, code := range s.ExpiredStatus {
+		if resp.StatusCode == code {
+			return true
+		}
+	}
+	if s.LoginURL != "" && resp.URL != nil {
+		u := resp.URL.String()
+		if strings.HasPrefix(s.LoginURL, "/") {
+			u = resp.URL.Path
+		}
+		if strings.HasPrefix(u, s.LoginURL) {
+			return true
+		}
+	}
+	if s.Marker != "" && bytes.Contains(resp.Body, []byte(s.Marker)) {
+		return true
+	}
+	return s.Expired != nil && s.Expired(resp)
+}
+
+//添加Session 依照添加順序匹配 (參考scrapingo.Session)
+func (c *Collector) AddSession(s *Session) error {
+	if err := s.register(); err != nil {
+		return err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.sessions = append(c.sessions, s)
+	return nil
+}
+
+//取得URL所對應的Session
+func (c *Collector) getSession(URL string) *Session {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, s := range c.sessions {
+		if s.Match(URL) {
+			return s
+		}
+	}
+	return nil
+}
+
+//登入時所使用的Collector 與c共用Transfer CookieStorage以及Logger
+//不進行去重以及URL限制 也不調用任何Callback
+func (c *Collector) loginCollector() *Collector {
+	return &Collector{
+		UserAgent:      c.UserAgent,
+		MaxBodySize:    c.MaxBodySize,
+		Encoding:       c.Encoding,
+		visitedStorage: defaultHasStorage(),
+		mu:             &sync.Mutex{},
+		transfer:       c.transfer,
+		cookies:        c.cookies,
+		robots:         c.robots,
+		normalizer:     c.normalizer,
+		fingerprinter:  c.fingerprinter,
+		logger:         c.logger,
+		LoggerMode:     c.LoggerMode,
+		errlogkey:      c.errlogkey,
+		requestlogkey:  c.requestlogkey,
+		resultlogkey:   c.resultlogkey,
+		ctx:            c.ctx,
+	}
+}
+
+//進行請求 URL符合Session時請求前確認已登入
+//Response判斷為Session過期時重新登入 並重新請求一次 仍然過期時返回ErrSessionExpired
+func (c *Collector) sessionFetch(req *Request, httpReq *http.Request) (*Response, error) {
+	s := c.getSession(req.URL.String())
+	if s == nil {
+		return c.fetch(req, httpReq)
+	}
+	generation, err := s.ensure(c)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.fetch(req, httpReq)
+	if resp == nil || !s.expired(resp) {
+		return resp, err
+	}
+	if err = s.relogin(c, generation); err != nil {
+		return nil, err
+	}
+	if httpReq, err = replayRequest(httpReq); err != nil {
+		return nil, err
+	}
+
+	//重新請求時不使用DiskCache 避免取得過期時的Response
+	noCache := req.NoCache
+	req.NoCache = true
+	resp, err = c.fetch(req, httpReq)
+	req.NoCache = noCache
+	if resp != nil && s.expired(resp) {
+		return nil, ErrSessionExpired
+	}
+	return resp, err
+}
@@ -0,0 +1,194 @@
+package scrapingo
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/xml"
+	"io/ioutil"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+//sitemap中的<url>或sitemap index中的<sitemap>
+type SitemapEntry struct {
+	Loc string
+	//未設置lastmod時為零值
+	LastMod    time.Time
+	ChangeFreq string
+	Priority   float64
+}
+
+//解析後的sitemap
+//urlset的內容在URLs中 sitemapindex的內容在Sitemaps中
+type SitemapData struct {
+	URLs     []SitemapEntry
+	Sitemaps []SitemapEntry
+}
+
+//sitemap的XML格式 同時對應urlset以及sitemapindex
+type sitemapXML struct {
+	URLs     []sitemapXMLEntry `xml:"url"`
+	Sitemaps []sitemapXMLEntry `xml:"sitemap"`
+}
+
+type sitemapXMLEntry struct {
+	Loc        string `xml:"loc"`
+	LastMod    string `xml:"lastmod"`
+	ChangeFreq string `xml:"changefreq"`
+	Priority   string `xml:"priority"`
+}
+
+func (e sitemapXMLEntry) entry() SitemapEntry {
+	entry := SitemapEntry{
+		Loc:        strings.TrimSpace(e.Loc),
+		LastMod:    parseW3CDate(e.LastMod),
+		ChangeFreq: strings.TrimSpace(e.ChangeFreq),
+	}
+	entry.Priority, _ = strconv.ParseFloat(strings.TrimSpace(e.Priority), 64)
+	return entry
+}
+
+//lastmod所使用的W3C Datetime格式
+var w3cLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04Z07:00",
+	"2006-01-02T15:04:05",
+	"2006-01-02",
+	"2006-01",
+	"2006",
+}
+
+//解析lastmod 無法解析時返回零值
+func parseW3CDate(s string) time.Time {
+	s = strings.TrimSpace(s)
+	for _, layout := range w3cLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+//解析sitemap或sitemap index body為gzip時會先進行解壓縮
+func ParseSitemap(body []byte) (*SitemapData, error) {
+	if len(body) > 2 && body[0] == 0x1f && body[1] == 0x8b {
+		gr, err := gzip.NewReader(bytes.NewReader(body))
+		if err != nil {
+			return nil, err
+		}
+		if body, err = ioutil.ReadAll(gr); err != nil {
+			return nil, err
+		}
+	}
+
+	var raw sitemapXML
+	if err := xml.Unmarshal(body, &raw); err != nil {
+		return nil, err
+	}
+	data := &SitemapData{}
+	for _, e := range raw.URLs {
+		data.URLs = append(data.URLs, e.entry())
+	}
+	for _, e := range raw.Sitemaps {
+		data.Sitemaps = append(data.Sitemaps, e.entry())
+	}
+	return data, nil
+}
+
+//讀取sitemap並將其中的URL轉為Request
+//sitemap index會遞迴讀取 gzip格式的sitemap會自動解壓縮
+//由Request返回的種子交由ConcurrentEngine或Collector進行爬取 例：
+//  s := &scrapingo.Sitemap{Parse: ParseArticle, FromRobots: true}
+//  seed, _ := s.Request("https://example.com")
+//  engine.Run(seed)
+type Sitemap struct {
+	//sitemap中URL所使用的ParseFunc
+	Parse ParseFunc
+	//sitemap中URL的其他RequestOption
+	Options []RequestOption
+	//只保留lastmod在Since之後的URL 未設置lastmod的URL會被保留
+	//同時適用於sitemap index中的sitemap
+	Since time.Time
+	//URL需符合其中任一個URLFilter 為空時不進行限制
+	Filters []URLFilter
+	//為true時從robots.txt中的Sitemap讀取 robots.txt沒有列出時使用/sitemap.xml
+	FromRobots bool
+}
+
+//返回讀取sitemap的種子Request
+//FromRobots為true時URL可為該網站的任一URL 會改為讀取該網站的robots.txt
+func (s *Sitemap) Request(URL string) (*Request, error) {
+	u, err := url.Parse(URL)
+	if err != nil {
+		return nil, err
+	}
+	if s.FromRobots {
+		robotsURL := &url.URL{Scheme: u.Scheme, Host: u.Host, Path: "/robots.txt"}
+		return NewRequest(robotsURL.String(),
+			ResponseParseFunction(s.parseRobots),
+			ErrPageParseFunction(s.parseRobots),
+		)
+	}
+	return s.sitemapRequest(URL)
+}
+
+//讀取sitemap的Request 不進行charset解碼
+func (s *Sitemap) sitemapRequest(URL string) (*Request, error) {
+	return NewRequest(URL, ParseFunctionWithError(s.parseSitemap), Raw(true))
+}
+
+//從robots.txt取得sitemap
+func (s *Sitemap) parseRobots(resp *Response) *ParseResult {
+	result := &ParseResult{}
+	var sitemaps []string
+	if resp.StatusCode == 200 {
+		sitemaps = ParseRobots(resp.Body).Sitemaps
+	}
+	if len(sitemaps) == 0 {
+		sitemaps = []string{"/sitemap.xml"}
+	}
+	for _, sitemap := range sitemaps {
+		if req, err := s.sitemapRequest(absoluteURL(resp.URL, sitemap)); err == nil {
+			result.Requests = append(result.Requests, req)
+		}
+	}
+	return result
+}
+
+//解析sitemap sitemap index中的sitemap會繼續讀取
+func (s *Sitemap) parseSitemap(resp *Response) (*ParseResult, error) {
+	data, err := ParseSitemap(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	result := &ParseResult{}
+	for _, entry := range data.Sitemaps {
+		if !s.fresh(entry) {
+			continue
+		}
+		req, err := s.sitemapRequest(absoluteURL(resp.URL, entry.Loc))
+		if err != nil {
+			continue
+		}
+		result.Requests = append(result.Requests, req)
+	}
+	for _, entry := range data.URLs {
+		link := absoluteURL(resp.URL, entry.Loc)
+		if !s.fresh(entry) || (len(s.Filters) > 0 && !matchFilters(s.Filters, link)) {
+			continue
+		}
+		req, err := NewRequest(link, append([]RequestOption{ParseFunction(s.Parse)}, s.Options...)...)
+		if err != nil {
+			continue
+		}
+		result.Requests = append(result.Requests, req)
+	}
+	return result, nil
+}
+
+//判斷lastmod是否在Since之後
+func (s *Sitemap) fresh(entry SitemapEntry) bool {
+	return s.Since.IsZero() || entry.LastMod.IsZero() || !entry.LastMod.Before(s.Since)
+}
@@ -1,21 +1,18 @@
 package scrapingo
 
 import (
-	"bufio"
 	"fmt"
 	"io"
 	"io/ioutil"
 	"math/rand"
 	"net/http"
+	"net/url"
 	"strings"
 	"sync"
 	"time"
 
 	"github.com/gobwas/glob"
 	"github.com/gobwas/glob/match"
-	"golang.org/x/net/html/charset"
-	"golang.org/x/text/encoding"
-	"golang.org/x/text/transform"
 )
 
 type Limiter struct {
@@ -62,6 +59,18 @@ func (l *Limiter) String() string {
 type Transfer struct {
 	Client   http.Client
 	Limiters []*Limiter
+	//請求失敗時的重試策略 為nil時不進行重試
+	Retry *RetryPolicy
+	//會傳入解析函式的StatusCode 為nil時只接受200
+	AcceptStatus []int
+	//請求時選擇所使用的Proxy 為nil時不使用 調用SetProxySwitcher進行設置
+	Proxies ProxySwitcher
+	//Respons的磁碟緩存 為nil時不進行緩存
+	Cache *DiskCache
+	//請求時依照URL所匹配的認證 (參考scrapingo.Authenticator)
+	Auths []*Auth
+	//CollectorOption Proxies設置失敗時的error 請求時返回
+	proxyErr error
 	rw       sync.RWMutex
 }
 
@@ -77,8 +86,91 @@ func (t *Transfer) getLimiter(URL string) *Limiter {
 	return nil
 }
 
-//模擬請求返回解碼後的html[]Byte 當[]ByteSize大於傳入的MAxBodySize時進行限制
-func (t *Transfer) do(req *http.Request, MaxBodySize int) ([]byte, error) {
+//模擬請求返回Response 當ResponsBodySize大於傳入的MaxBodySize時進行限制
+//設置了DiskCache時 會優先使用緩存中的Response
+//StatusCode不被接受時同時返回Response以及*HTTPStatusError
+func (t *Transfer) do(r *Request, req *http.Request, MaxBodySize int) (*Response, error) {
+	if t.proxyErr != nil {
+		return nil, t.proxyErr
+	}
+	r.CacheStatus = ""
+	if t.Cache == nil || r.NoCache || r.Download != nil || !cacheableMethod(req.Method) {
+		return t.retryDo(r, req, MaxBodySize)
+	}
+	return t.cacheDo(r, req, MaxBodySize)
+}
+
+//進行請求 請求失敗時依照RetryPolicy進行重試
+//並將當前的請求次數記錄至Request.Attempt
+func (t *Transfer) retryDo(r *Request, req *http.Request, MaxBodySize int) (*Response, error) {
+	for attempt := 1; ; attempt++ {
+		r.Attempt = attempt
+
+		if attempt > 1 {
+			var err error
+			if req, err = replayRequest(req); err != nil {
+				return nil, err
+			}
+		}
+
+		resp, err := t.authRoundTrip(r, req, MaxBodySize)
+
+		if req.Context().Err() != nil || !t.Retry.retry(attempt, resp, err) {
+			if err != nil {
+				return nil, err
+			}
+			if !t.acceptStatus(resp.StatusCode) && !(r.Download != nil && isDownloadStatus(resp.StatusCode)) {
+				return resp, &HTTPStatusError{
+					StatusCode: resp.StatusCode,
+					Header:     resp.Header,
+					Body:       resp.Body,
+					URL:        resp.URL,
+				}
+			}
+			return resp, nil
+		}
+
+		select {
+		case <-time.After(t.Retry.backoff(attempt, resp)):
+		case <-req.Context().Done():
+			return nil, req.Context().Err()
+		}
+	}
+}
+
+//複製請求並重新讀取Body 用於重新發送 Body無法重新讀取時返回ErrBodyNotReplayable
+func replayRequest(req *http.Request) (*http.Request, error) {
+	if req.Body != nil && req.GetBody == nil {
+		return nil, ErrBodyNotReplayable
+	}
+	req = req.Clone(req.Context())
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		req.Body = body
+	}
+	return req, nil
+}
+
+//判斷該StatusCode是否會傳入解析函式
+func (t *Transfer) acceptStatus(code int) bool {
+	if t.AcceptStatus == nil {
+		return code == http.StatusOK
+	}
+	for _, c := range t.AcceptStatus {
+		if c == code {
+			return true
+		}
+	}
+	return false
+}
+
+//進行單次請求 請求時受到所匹配的Limiter限制
+//設置了ProxySwitcher時會選擇Proxy並記錄至Request.Proxy
+//Request設置了Download時 ResponsBody將直接寫入檔案
+func (t *Transfer) roundTrip(r *Request, req *http.Request, MaxBodySize int) (*Response, error) {
 	limiter := t.getLimiter(req.URL.String())
 
 	if limiter != nil {
@@ -94,45 +186,99 @@ func (t *Transfer) do(req *http.Request, MaxBodySize int) ([]byte, error) {
 		}()
 	}
 
+	if t.Proxies != nil {
+		proxy, err := t.Proxies.Proxy(req)
+		if err != nil {
+			return nil, err
+		}
+		r.Proxy = proxy
+		req = withProxy(req, proxy)
+	}
+
+	var offset int64
+	if r.Download != nil {
+		req, offset = r.Download.setRange(req)
+	}
+
+	//http.Client會將CookieStorage中的Cookie直接加入至Header
+	//每次請求使用Header的複製 避免重試時重複加入Cookie
+	req = req.Clone(req.Context())
 	resp, err := t.Client.Do(req)
+	if r.Proxy != nil && t.Proxies != nil {
+		t.Proxies.Report(r.Proxy, proxyErr(resp, err))
+	}
 	if err != nil {
 		return nil, err
 	}
+	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("scrapingo: Respons StatusCode is %d", resp.StatusCode)
+	if r.Download != nil && isDownloadStatus(resp.StatusCode) {
+		if err = r.Download.save(r, resp, offset); err != nil {
+			return nil, err
+		}
+		return newResponse(resp.StatusCode, resp.Header, resp.Request.URL, nil), nil
 	}
-	return fetch(resp.Body, MaxBodySize)
-}
-
-//進行html解碼以及限制返回的ResponsBodySize
-func fetch(Body io.Reader, MaxBodySize int) ([]byte, error) {
-	BufReader := bufio.NewReader(Body)
 
-	e, err := determinEncoding(BufReader)
+	body, err := decompress(resp)
+	if err != nil {
+		return nil, err
+	}
+	raw, err := fetch(body, MaxBodySize)
 	if err != nil {
 		return nil, err
 	}
+	return newResponse(resp.StatusCode, resp.Header, resp.Request.URL, raw), nil
+}
 
-	var BodyReader io.Reader
-	BodyReader = BufReader
+//讀取ResponsBody並限制其Size
+func fetch(Body io.Reader, MaxBodySize int) ([]byte, error) {
+	if MaxBodySize > 0 {
+		Body = io.LimitReader(Body, int64(MaxBodySize))
+	}
+	return ioutil.ReadAll(Body)
+}
 
-	if MaxBodySize > 0 && BufReader.Size() > MaxBodySize {
-		BodyReader = io.LimitReader(BodyReader, int64(MaxBodySize))
+//將原始的ResponsBody轉為Response 此時Body與RawBody相同
+//charset解碼由Collector進行 (參考Collector.decodeBody)
+func newResponse(StatusCode int, Header http.Header, URL *url.URL, raw []byte) *Response {
+	return &Response{
+		StatusCode: StatusCode,
+		Header:     Header,
+		URL:        URL,
+		Body:       raw,
+		RawBody:    raw,
 	}
+}
 
-	NewReader := transform.NewReader(BodyReader, e.NewDecoder())
-	return ioutil.ReadAll(NewReader)
+//設置Transfer所使用的ProxySwitcher 傳入nil時不使用Proxy
+//Client.Transport為nil時會使用http.DefaultTransport的複製
+//Client.Transport不為*http.Transport時返回ErrUnsupportedTransport
+func (t *Transfer) SetProxySwitcher(p ProxySwitcher) error {
+	if p == nil {
+		t.Proxies, t.proxyErr = nil, nil
+		return nil
+	}
+	if t.Client.Transport == nil {
+		t.Client.Transport = http.DefaultTransport.(*http.Transport).Clone()
+	}
+	transport, ok := t.Client.Transport.(*http.Transport)
+	if !ok {
+		return ErrUnsupportedTransport
+	}
+	transport.Proxy = proxyFromContext
+	t.Proxies, t.proxyErr = p, nil
+	return nil
 }
 
-//取1024byt探測html所使用的編碼方式
-func determinEncoding(r *bufio.Reader) (encoding.Encoding, error) {
-	bytes, err := r.Peek(1024)
+//判斷請求結果是否為Proxy的錯誤 Proxy要求驗證時也視為錯誤
+func proxyErr(resp *http.Response, err error) error {
 	if err != nil {
-		return nil, err
+		return err
+	}
+	if resp.StatusCode == http.StatusProxyAuthRequired {
+		return fmt.Errorf("scrapingo: Proxy StatusCode is %d", resp.StatusCode)
 	}
-	e, _, _ := charset.DetermineEncoding(bytes, "")
-	return e, nil
+	return nil
 }
 
 //添加limiter至Transfer中當register()返回error時添加失敗
@@ -156,8 +302,20 @@ func (t *Transfer) AddLimiters(limiters []*Limiter) (err error) {
 }
 func (t *Transfer) String() string {
 	str := fmt.Sprintf("Trandfer:\n\t\t|-RequestTimeOut: %.3fs", t.Client.Timeout.Seconds())
+	if t.Retry != nil {
+		str = strings.Join([]string{str, "|-RetryPolicy:", "|\t|-" + t.Retry.String()}, "\n\t\t")
+	}
+	if t.Cache != nil {
+		str = strings.Join([]string{str, "|-DiskCache:", "|\t|-" + t.Cache.String()}, "\n\t\t")
+	}
+	if t.Proxies != nil {
+		str = strings.Join([]string{str, "|-ProxySwitcher:", fmt.Sprintf("|\t|-%v", t.Proxies)}, "\n\t\t")
+	}
 	for i, limiter := range t.Limiters {
 		str = strings.Join([]string{str, fmt.Sprintf("|-limiter%d:", i+1), "|\t|-" + limiter.String()}, "\n\t\t")
 	}
+	for i, auth := range t.Auths {
+		str = strings.Join([]string{str, fmt.Sprintf("|-auth%d:", i+1), "|\t|-" + auth.String()}, "\n\t\t")
+	}
 	return str
 }
@@ -0,0 +1,251 @@
+package scrapingo
+
+import (
+	"bytes"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/antchfx/htmlquery"
+	"github.com/antchfx/xmlquery"
+	"github.com/antchfx/xpath"
+	"golang.org/x/net/html"
+)
+
+//當Response為HTML或XML時 對符合XPath的每個節點調用
+type XMLCallback func(*XMLElement)
+
+//Id為自行定義的唯一識別調用 調用OnXMLDetach刪除時所使用
+type XMLCallbackContainer struct {
+	Id    int
+	XPath string
+	Func  XMLCallback
+	expr  *xpath.Expr
+}
+
+//符合XPath的HTML或XML節點
+//Response為HTML時DOM為*html.Node XML時為*xmlquery.Node
+type XMLElement struct {
+	//節點的標籤名稱
+	Name string
+	//該節點在所有符合的節點中的位子
+	Index    int
+	Request  *Request
+	Response *Response
+	//節點所對應的*html.Node或*xmlquery.Node
+	DOM interface{}
+
+	//解析頁面中相對URL時的基準
+	base   *url.URL
+	result *ParseResult
+}
+
+//初始化XMLElement n為*html.Node或*xmlquery.Node
+func newXMLElement(resp *Response, base *url.URL, result *ParseResult, n interface{}, index int) *XMLElement {
+	e := &XMLElement{
+		Index:    index,
+		Request:  resp.Request,
+		Response: resp,
+		DOM:      n,
+		base:     base,
+		result:   result,
+	}
+	switch n := n.(type) {
+	case *html.Node:
+		e.Name = n.Data
+	case *xmlquery.Node:
+		e.Name = n.Data
+	}
+	return e
+}
+
+//返回節點中所有的文字
+func (e *XMLElement) Text() string {
+	switch n := e.DOM.(type) {
+	case *html.Node:
+		return htmlquery.InnerText(n)
+	case *xmlquery.Node:
+		return n.InnerText()
+	}
+	return ""
+}
+
+//返回節點的屬性值 不存在時返回空字串
+func (e *XMLElement) Attr(k string) string {
+	switch n := e.DOM.(type) {
+	case *html.Node:
+		return htmlquery.SelectAttr(n, k)
+	case *xmlquery.Node:
+		return n.SelectAttr(k)
+	}
+	return ""
+}
+
+//返回第一個符合XPath的子節點的文字
+func (e *XMLElement) ChildText(xpathQuery string) string {
+	if nodes := e.find(xpathQuery); len(nodes) > 0 {
+		return strings.TrimSpace(nodes[0].Text())
+	}
+	return ""
+}
+
+//返回所有符合XPath的子節點的文字
+func (e *XMLElement) ChildTexts(xpathQuery string) []string {
+	var texts []string
+	for _, n := range e.find(xpathQuery) {
+		texts = append(texts, strings.TrimSpace(n.Text()))
+	}
+	return texts
+}
+
+//返回第一個符合XPath的子節點的屬性值
+func (e *XMLElement) ChildAttr(xpathQuery, attr string) string {
+	if nodes := e.find(xpathQuery); len(nodes) > 0 {
+		return nodes[0].Attr(attr)
+	}
+	return ""
+}
+
+//返回所有符合XPath的子節點的屬性值
+func (e *XMLElement) ChildAttrs(xpathQuery, attr string) []string {
+	var attrs []string
+	for _, n := range e.find(xpathQuery) {
+		attrs = append(attrs, n.Attr(attr))
+	}
+	return attrs
+}
+
+//對每個符合XPath的子節點調用f
+func (e *XMLElement) ForEach(xpathQuery string, f func(int, *XMLElement)) {
+	for i, n := range e.find(xpathQuery) {
+		f(i, n)
+	}
+}
+
+//將頁面中的相對URL轉為絕對URL 無法解析時返回空字串
+func (e *XMLElement) AbsoluteURL(link string) string {
+	return absoluteURL(e.base, link)
+}
+
+//以頁面URL為基準初始化新的Request
+func (e *XMLElement) NewRequest(link string, options ...RequestOption) (*Request, error) {
+	u := e.AbsoluteURL(link)
+	if u == "" {
+		return nil, ErrURLMiss
+	}
+	return NewRequest(u, options...)
+}
+
+//以頁面URL為基準初始化新的Request 並加入至ParseResult.Requests
+func (e *XMLElement) Follow(link string, options ...RequestOption) error {
+	req, err := e.NewRequest(link, options...)
+	if err != nil {
+		return err
+	}
+	e.result.Requests = append(e.result.Requests, req)
+	return nil
+}
+
+//將item加入至ParseResult.Items
+func (e *XMLElement) AddItem(item interface{}) {
+	e.result.Items = append(e.result.Items, item)
+}
+
+//以該節點為起點查詢XPath XPath不合法時返回nil
+func (e *XMLElement) find(xpathQuery string) []*XMLElement {
+	var elements []*XMLElement
+	switch n := e.DOM.(type) {
+	case *html.Node:
+		nodes, err := htmlquery.QueryAll(n, xpathQuery)
+		if err != nil {
+			return nil
+		}
+		for i, node := range nodes {
+			elements = append(elements, newXMLElement(e.Response, e.base, e.result, node, i))
+		}
+	case *xmlquery.Node:
+		nodes, err := xmlquery.QueryAll(n, xpathQuery)
+		if err != nil {
+			return nil
+		}
+		for i, node := range nodes {
+			elements = append(elements, newXMLElement(e.Response, e.base, e.result, node, i))
+		}
+	}
+	return elements
+}
+
+//判斷Response是否為XML
+func isXML(resp *Response) bool {
+	ct := resp.ContentType()
+	return ct == "text/xml" || ct == "application/xml" || strings.HasSuffix(ct, "+xml")
+}
+
+//XML宣告中的encoding
+var xmlEncodingDecl = regexp.MustCompile(`^(\s*<\?xml[^>]*?\s)encoding\s*=\s*["'][^"']*["']`)
+
+//解析XML ResponsBody已解碼為UTF-8 需忽略XML宣告中的encoding
+func parseXML(body []byte) (*xmlquery.Node, error) {
+	body = xmlEncodingDecl.ReplaceAll(body, []byte(`${1}encoding="UTF-8"`))
+	return xmlquery.Parse(bytes.NewReader(body))
+}
+
+//Id為刪除時的唯一標示 設置XMLCallback
+//Response為HTML或XML時 會對每個符合XPath的節點調用所設置的XMLCallback
+func (c *Collector) OnXML(Id int, xpathQuery string, f XMLCallback) error {
+	expr, err := xpath.Compile(xpathQuery)
+	if err != nil {
+		return err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.xmlcallbacks = append(c.xmlcallbacks, XMLCallbackContainer{Id: Id, XPath: xpathQuery, Func: f, expr: expr})
+	return nil
+}
+
+//輸入指定Id會刪除對應的XMLCallback
+func (c *Collector) OnXMLDetach(Id int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for index, callback := range c.xmlcallbacks {
+		if callback.Id == Id {
+			c.xmlcallbacks = append(c.xmlcallbacks[:index], c.xmlcallbacks[index+1:]...)
+		}
+	}
+}
+
+//依照Content-Type解析ResponsBody 並調用自定義的HTMLCallback XMLCallback以及LinkExtractor
+//HTML只會解析一次 結果會加入至result
+func (c *Collector) handleOnMarkup(resp *Response, result *ParseResult) error {
+	if len(c.htmlcallbacks) == 0 && len(c.xmlcallbacks) == 0 && len(c.linkextractors) == 0 {
+		return nil
+	}
+	switch {
+	case isHTML(resp):
+		doc, err := html.Parse(bytes.NewReader(resp.Body))
+		if err != nil {
+			return err
+		}
+		base := baseURL(doc, resp.URL)
+		c.handleOnHTML(resp, doc, base, result)
+		for _, callback := range c.xmlcallbacks {
+			for i, n := range htmlquery.QuerySelectorAll(doc, callback.expr) {
+				callback.Func(newXMLElement(resp, base, result, n, i))
+			}
+		}
+		if err = c.handleLinkExtractors(doc, base, result); err != nil {
+			return err
+		}
+	case isXML(resp) && len(c.xmlcallbacks) > 0:
+		doc, err := parseXML(resp.Body)
+		if err != nil {
+			return err
+		}
+		for _, callback := range c.xmlcallbacks {
+			for i, n := range xmlquery.QuerySelectorAll(doc, callback.expr) {
+				callback.Func(newXMLElement(resp, resp.URL, result, n, i))
+			}
+		}
+	}
+	return nil
+}